@@ -41,6 +41,9 @@ func main() {
 	}
 	providers.Configure(pcfg, tokenAuth)
 
+	// HMAC key signing the pagination cursors, shared by all the instances
+	providers.CursorKey = []byte("cursor-secret-of-32-bytes-at-least")
+
 	// HTTP service
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
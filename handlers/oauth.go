@@ -10,8 +10,13 @@ import (
 	"github.com/go-chi/jwtauth"
 	"github.com/go-social/social"
 	"github.com/go-social/social/providers"
+	"github.com/go-social/social/providers/telemetry"
 )
 
+// OAuthTelemetry records the funnel metrics of the OAuth flows, and the
+// spans of the token exchanges, with the otel global providers
+var OAuthTelemetry = telemetry.NewOAuth()
+
 func ProviderCtx(oauthErrorFn ErrorHandlerFunc) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -35,6 +40,7 @@ func OAuth(oauthErrorFn ErrorHandlerFunc) func(w http.ResponseWriter, r *http.Re
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		oauth := ctx.Value(ProviderOAuthCtxKey).(social.OAuth)
+		OAuthTelemetry.Started(ctx, oauth.ProviderID())
 
 		state := jwtauth.Claims{
 			"sub":      "OAuthCallback",
@@ -48,6 +54,7 @@ func OAuth(oauthErrorFn ErrorHandlerFunc) func(w http.ResponseWriter, r *http.Re
 
 		authURL, err := oauth.AuthCodeURL(r, state)
 		if err != nil {
+			OAuthTelemetry.Failed(ctx, oauth.ProviderID(), err)
 			oauthErrorFn(w, r, err)
 			return
 		}
@@ -56,6 +63,26 @@ func OAuth(oauthErrorFn ErrorHandlerFunc) func(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// OAuthAuthenticator is jwtauth.Authenticator for the OAuth callback state
+// token, also recording the OAuth flows failed on an invalid or expired
+// state, which never reach OAuthCallback
+func OAuthAuthenticator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		token, _, err := jwtauth.FromContext(ctx)
+		if err == nil && (token == nil || !token.Valid) {
+			err = jwtauth.ErrUnauthorized
+		}
+		if err != nil {
+			oauth := ctx.Value(ProviderOAuthCtxKey).(social.OAuth)
+			OAuthTelemetry.Failed(ctx, oauth.ProviderID(), providers.ErrAuthFailed.Err(err))
+			http.Error(w, http.StatusText(401), 401)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func OAuthCallback(oauthCallbackFn CallbackHandlerFunc) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
@@ -66,10 +93,13 @@ func OAuthCallback(oauthCallbackFn CallbackHandlerFunc) func(w http.ResponseWrit
 		oauth := ctx.Value(ProviderOAuthCtxKey).(social.OAuth)
 
 		defer func() {
+			OAuthTelemetry.Done(ctx, oauth.ProviderID(), err)
 			oauthCallbackFn(w, r, mcreds, providerUser, err)
 		}()
 
-		mcreds, err = oauth.Exchange(ctx, r)
+		exchangeCtx, endSpan := OAuthTelemetry.StartSpan(ctx, oauth.ProviderID(), "Exchange")
+		mcreds, err = oauth.Exchange(exchangeCtx, r)
+		endSpan(err)
 		if err != nil {
 			return
 		}
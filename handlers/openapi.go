@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// OpenAPI is an OpenAPI 3 document describing the handler routes. It's
+// generated from the router and the payload types, see NewOpenAPI.
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type OpenAPIOperation struct {
+	Summary    string                      `json:"summary,omitempty"`
+	Parameters []*OpenAPIParameter         `json:"parameters,omitempty"`
+	Responses  map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is an OpenAPI schema object, an empty schema matches any value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// apiOperation documents a route, see documented
+type apiOperation struct {
+	Summary  string
+	Query    []*OpenAPIParameter
+	Status   int
+	Response interface{} // payload value, nil for responses without a body
+}
+
+// apiHandler is a route handler along with its OpenAPI operation, found
+// by NewOpenAPI when walking the routes
+type apiHandler struct {
+	http.Handler
+	op apiOperation
+}
+
+// documented returns the handler documenting its route with op
+func documented(h http.HandlerFunc, op apiOperation) http.Handler {
+	return &apiHandler{Handler: h, op: op}
+}
+
+// Additional payloads documented as schema components, even though no
+// route renders them directly
+var apiPayloads = []interface{}{
+	&PostListResponse{},
+	&ErrorResponse{},
+}
+
+var routeParamRe = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// NewOpenAPI generates the OpenAPI document for the routes
+func NewOpenAPI(routes chi.Routes) (*OpenAPI, error) {
+	doc := &OpenAPI{
+		OpenAPI:    "3.0.3",
+		Info:       OpenAPIInfo{Title: "go-social", Version: "1.0.0"},
+		Paths:      map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{Schemas: map[string]*Schema{}},
+	}
+
+	err := chi.Walk(routes, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = strings.Replace(route, "/*/", "/", -1)
+		path := routeParamRe.ReplaceAllString(route, "{$1}")
+
+		op := &OpenAPIOperation{Responses: map[string]*OpenAPIResponse{}}
+		for _, m := range routeParamRe.FindAllStringSubmatch(route, -1) {
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+
+		if h, ok := handler.(*apiHandler); ok {
+			desc := h.op
+			op.Summary = desc.Summary
+			op.Parameters = append(op.Parameters, desc.Query...)
+
+			resp := &OpenAPIResponse{Description: http.StatusText(desc.Status)}
+			if desc.Response != nil {
+				resp.Content = map[string]*OpenAPIMediaType{
+					"application/json": {Schema: doc.schemaFor(reflect.TypeOf(desc.Response))},
+				}
+			}
+			op.Responses[statusKey(desc.Status)] = resp
+		} else {
+			op.Responses["default"] = &OpenAPIResponse{Description: "Undocumented response"}
+		}
+
+		if _, ok := doc.Paths[path]; !ok {
+			doc.Paths[path] = map[string]*OpenAPIOperation{}
+		}
+		doc.Paths[path][strings.ToLower(method)] = op
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range apiPayloads {
+		doc.schemaFor(reflect.TypeOf(v))
+	}
+	return doc, nil
+}
+
+// OpenAPISpec serves the OpenAPI document of the routes. The document
+// is generated on each request, so it stays in sync with the router.
+func OpenAPISpec(routes chi.Routes) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc, err := NewOpenAPI(routes)
+		if err != nil {
+			render.Render(w, r, NewErrorResponse(http.StatusInternalServerError, err))
+			return
+		}
+
+		// Routes are relative to wherever the router is mounted
+		base := strings.TrimSuffix(r.URL.Path, "/openapi.json")
+		if base != "" {
+			doc.Servers = []OpenAPIServer{{URL: base}}
+		}
+
+		render.JSON(w, r, doc)
+	}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaFor returns the schema of a Go type as encoded by encoding/json.
+// Named struct types are registered as components and referenced.
+func (doc *OpenAPI) schemaFor(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string", Nullable: nullable}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Nullable: nullable}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Nullable: nullable}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float", Nullable: nullable}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{Type: "array", Items: doc.schemaFor(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: doc.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return doc.structSchema(t)
+		}
+		if _, ok := doc.Components.Schemas[t.Name()]; !ok {
+			// Register first, so recursive types resolve to a reference
+			doc.Components.Schemas[t.Name()] = &Schema{}
+			*doc.Components.Schemas[t.Name()] = *doc.structSchema(t)
+		}
+		ref := &Schema{Ref: "#/components/schemas/" + t.Name()}
+		if nullable {
+			// The siblings of a $ref are ignored, so it's wrapped to be nullable
+			return &Schema{AllOf: []*Schema{ref}, Nullable: true}
+		}
+		return ref
+	}
+
+	// interface{} and anything else, matches any value
+	return &Schema{}
+}
+
+func (doc *OpenAPI) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		// Embedded structs are flattened into the parent, as with encoding/json
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded := doc.structSchema(ft)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = doc.schemaFor(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
+
+func statusKey(status int) string {
+	if status == 0 {
+		return "default"
+	}
+	return strconv.Itoa(status)
+}
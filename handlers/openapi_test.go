@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi"
+)
+
+func testRoutes(t *testing.T) chi.Routes {
+	routes, ok := Routes(nil, nil).(chi.Routes)
+	if !ok {
+		t.Fatal("expecting the routes to be a chi router")
+	}
+	return routes
+}
+
+func TestNewOpenAPI(t *testing.T) {
+	doc, err := NewOpenAPI(testRoutes(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := func(name string) *Schema {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	tests := map[string]*Schema{
+		"/capabilities": {Type: "array", Items: &Schema{AllOf: []*Schema{ref("ProviderResponse")}, Nullable: true}, Nullable: true},
+		"/status":       {Type: "array", Items: &Schema{AllOf: []*Schema{ref("StatusResponse")}, Nullable: true}, Nullable: true},
+		"/openapi.json": {Type: "object", AdditionalProperties: &Schema{}, Nullable: true},
+	}
+	for path, want := range tests {
+		op := doc.Paths[path]["get"]
+		if op == nil || op.Responses["200"] == nil || op.Responses["200"].Content["application/json"] == nil {
+			t.Errorf("%s: expecting a documented GET with a json response, got %+v", path, op)
+			continue
+		}
+		if got := op.Responses["200"].Content["application/json"].Schema; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: unexpected response schema %s", path, schemaJSON(got))
+		}
+	}
+
+	// Path parameters, without their regexp
+	callback := doc.Paths["/{provider}/callback"]["get"]
+	if callback == nil || len(callback.Parameters) != 2 || callback.Parameters[0].Name != "provider" ||
+		callback.Parameters[0].In != "path" || callback.Parameters[1].Name != "state" {
+		t.Errorf("unexpected callback operation %+v", callback)
+	}
+	if _, ok := doc.Paths["/{provider}/"]["get"].Responses["302"]; !ok {
+		t.Errorf("expecting the oauth redirect to be documented")
+	}
+
+	// Components, with the nullable fields of named types wrapped in allOf
+	schemas := doc.Components.Schemas
+	for _, name := range []string{"ProviderResponse", "Capabilities", "StatusResponse", "UserResponse", "User", "PostListResponse", "Post", "CursorResponse", "ErrorResponse"} {
+		if schemas[name] == nil {
+			t.Errorf("expecting a %s component", name)
+		}
+	}
+	if got, want := schemas["PostListResponse"].Properties["cursor"], (&Schema{AllOf: []*Schema{ref("CursorResponse")}, Nullable: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting the cursor to be a nullable reference, got %s", schemaJSON(got))
+	}
+	if got := schemas["Post"].Properties["shared_post"]; got == nil || len(got.AllOf) != 1 || got.AllOf[0].Ref != ref("Post").Ref || !got.Nullable {
+		t.Errorf("expecting a nullable reference to the recursive Post, got %s", schemaJSON(got))
+	}
+	if got, want := schemas["StatusResponse"].Properties["retry_at"], (&Schema{Type: "string", Format: "date-time", Nullable: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected retry_at schema %s", schemaJSON(got))
+	}
+	if got := schemas["StatusResponse"].Required; !reflect.DeepEqual(got, []string{"provider", "state"}) {
+		t.Errorf("expecting the omitempty fields not to be required, got %v", got)
+	}
+	if _, ok := schemas["ErrorResponse"].Properties["HTTPStatusCode"]; ok {
+		t.Errorf("expecting the json:\"-\" fields to be left out")
+	}
+	if _, ok := schemas["CursorResponse"].Properties["err"]; ok {
+		t.Errorf("expecting the unexported fields to be left out")
+	}
+	if len(schemas["UserResponse"].Properties) == 0 || !reflect.DeepEqual(schemas["UserResponse"].Properties, schemas["User"].Properties) {
+		t.Errorf("expecting the embedded user to be flattened, got %s", schemaJSON(schemas["UserResponse"]))
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	r := chi.NewRouter()
+	r.Mount("/auth", Routes(nil, nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/auth/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expecting 200, got %d: %s", w.Code, w.Body)
+	}
+
+	var doc OpenAPI
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "/auth" {
+		t.Errorf("expecting the mount path as server, got %+v", doc.Servers)
+	}
+	if doc.OpenAPI != "3.0.3" || doc.Paths["/openapi.json"] == nil {
+		t.Errorf("unexpected document %s", w.Body)
+	}
+}
+
+func schemaJSON(s *Schema) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
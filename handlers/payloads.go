@@ -1 +1,162 @@
 package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+// ProviderResponse is a registered social provider
+type ProviderResponse struct {
+	ID           string                 `json:"id"`
+	Capabilities providers.Capabilities `json:"capabilities"`
+}
+
+func (pr *ProviderResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// NewProviderIDsResponse returns the ids of the registered providers,
+// sorted
+func NewProviderIDsResponse() []string {
+	ids := []string{}
+	for id := range providers.Registry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// NewProviderListResponse returns the registered providers, sorted by id
+func NewProviderListResponse() []render.Renderer {
+	list := []render.Renderer{}
+	for _, id := range NewProviderIDsResponse() {
+		list = append(list, &ProviderResponse{ID: id, Capabilities: providers.Registry[id].Capabilities})
+	}
+	return list
+}
+
+// StatusResponse is the circuit breaker state of a provider, or of one of
+// its endpoints
+type StatusResponse struct {
+	Provider string     `json:"provider"`
+	Endpoint string     `json:"endpoint,omitempty"`
+	State    string     `json:"state"`
+	RetryAt  *time.Time `json:"retry_at,omitempty"`
+}
+
+func (sr *StatusResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// NewStatusListResponse returns the circuit breaker states of the
+// registered providers, sorted by provider id. Providers which weren't
+// called yet are closed.
+func NewStatusListResponse(circuits *providers.CircuitBreakers) []render.Renderer {
+	byProvider := map[string][]providers.CircuitStatus{}
+	for _, s := range circuits.Status() {
+		byProvider[s.Provider] = append(byProvider[s.Provider], s)
+	}
+
+	list := []render.Renderer{}
+	for _, id := range NewProviderIDsResponse() {
+		status, ok := byProvider[id]
+		if !ok {
+			list = append(list, &StatusResponse{Provider: id, State: providers.CircuitClosed.String()})
+			continue
+		}
+		for _, s := range status {
+			sr := &StatusResponse{Provider: id, Endpoint: s.Endpoint, State: s.State.String()}
+			if !s.RetryAt.IsZero() {
+				retryAt := s.RetryAt
+				sr.RetryAt = &retryAt
+			}
+			list = append(list, sr)
+		}
+	}
+	return list
+}
+
+// UserResponse is a provider user profile, ie. as returned to
+// the client from a CallbackHandlerFunc
+type UserResponse struct {
+	*social.User
+}
+
+func NewUserResponse(user *social.User) *UserResponse {
+	return &UserResponse{User: user}
+}
+
+func (ur *UserResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// PostListResponse is a page of provider posts
+type PostListResponse struct {
+	Posts  social.Posts    `json:"posts"`
+	Cursor *CursorResponse `json:"cursor,omitempty"`
+}
+
+func NewPostListResponse(posts social.Posts, cursor *providers.Cursor) *PostListResponse {
+	if posts == nil {
+		posts = social.Posts{}
+	}
+	return &PostListResponse{Posts: posts, Cursor: NewCursorResponse(cursor)}
+}
+
+func (pr *PostListResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// CursorResponse holds the opaque tokens of the next and previous pages,
+// to be sent back as the `cursor` query argument
+type CursorResponse struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+
+	err error // encoding the tokens
+}
+
+func NewCursorResponse(cursor *providers.Cursor) *CursorResponse {
+	if cursor == nil {
+		return nil
+	}
+	cr := &CursorResponse{}
+	cr.Next, cr.err = cursor.NextToken()
+	if cr.err == nil {
+		cr.Prev, cr.err = cursor.PrevToken()
+	}
+	return cr
+}
+
+// Render fails with the error encoding the tokens, ie. ErrNoCursorKey
+func (cr *CursorResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return cr.err
+}
+
+// ErrorResponse is the payload rendered for handler errors
+type ErrorResponse struct {
+	HTTPStatusCode int    `json:"-"`
+	Code           int    `json:"code,omitempty"`
+	Message        string `json:"message"`
+}
+
+func NewErrorResponse(status int, err error) *ErrorResponse {
+	resp := &ErrorResponse{HTTPStatusCode: status, Message: err.Error()}
+	var e *providers.Error
+	if errors.As(err, &e) {
+		resp.Code = e.Code
+		resp.Message = e.Msg
+	}
+	return resp
+}
+
+func (e *ErrorResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, e.HTTPStatusCode)
+	return nil
+}
@@ -17,37 +17,78 @@ type CallbackHandlerFunc func(w http.ResponseWriter, r *http.Request, creds []so
 func Routes(oauthErrorFn ErrorHandlerFunc, oauthCallbackFn CallbackHandlerFunc) http.Handler {
 	r := chi.NewRouter()
 
-	r.Get("/", ListProviders)
+	r.Method("GET", "/", documented(ListProviders, apiOperation{
+		Summary:  "List the registered provider ids",
+		Status:   http.StatusOK,
+		Response: []string{},
+	}))
+	r.Method("GET", "/capabilities", documented(ListCapabilities, apiOperation{
+		Summary:  "List the registered providers with their capabilities",
+		Status:   http.StatusOK,
+		Response: []*ProviderResponse{},
+	}))
+	r.Method("GET", "/openapi.json", documented(OpenAPISpec(r), apiOperation{
+		Summary:  "OpenAPI document of this service",
+		Status:   http.StatusOK,
+		Response: map[string]interface{}{},
+	}))
+	r.Method("GET", "/status", documented(ProviderStatus, apiOperation{
+		Summary:  "List the circuit breaker state of the providers",
+		Status:   http.StatusOK,
+		Response: []*StatusResponse{},
+	}))
 
 	r.Route("/{provider}", func(r chi.Router) {
 		r.Use(ProviderCtx(oauthErrorFn))
 
-		r.Get("/", OAuth(oauthErrorFn)) // open
+		// open
+		r.Method("GET", "/", documented(OAuth(oauthErrorFn), apiOperation{
+			Summary: "Redirect to the provider to start the OAuth flow",
+			Query: []*OpenAPIParameter{
+				{Name: "perm", In: "query", Description: "requested permission: r, w or rw", Schema: &Schema{Type: "string"}},
+			},
+			Status: http.StatusFound,
+		}))
 
 		r.Group(func(r chi.Router) {
 			// secure, via jwt state token
 			r.Use(jwtauth.Verify(providers.TokenAuth, tokenFromQuery("state")))
-			r.Use(jwtauth.Authenticator)
-			r.Get("/callback", OAuthCallback(oauthCallbackFn))
+			r.Use(OAuthAuthenticator)
+			r.Method("GET", "/callback", documented(OAuthCallback(oauthCallbackFn), apiOperation{
+				Summary: "Complete the OAuth flow",
+				Query: []*OpenAPIParameter{
+					{Name: "state", In: "query", Description: "signed state token", Required: true, Schema: &Schema{Type: "string"}},
+				},
+				Status:   http.StatusOK,
+				Response: &UserResponse{},
+			}))
 		})
 	})
 
 	// TODO: this needs to be secured as well, as
 	// its a callback router
-	r.Get("/loopback/{route}", Loopback)
+	r.Method("GET", "/loopback/{route}", documented(Loopback, apiOperation{
+		Summary: "Redirect the client back into the router",
+		Status:  http.StatusFound,
+	}))
 
 	return r
 }
 
+// ListProviders lists the ids of the registered providers
 func ListProviders(w http.ResponseWriter, r *http.Request) {
-	plist := []string{}
-	for id, _ := range providers.Registry {
-		plist = append(plist, id)
-	}
+	render.JSON(w, r, NewProviderIDsResponse())
+}
 
-	// TODO: make payloads for all request / response objects, and render.Render()
-	render.JSON(w, r, plist)
+// ListCapabilities lists the registered providers with the session
+// operations they support
+func ListCapabilities(w http.ResponseWriter, r *http.Request) {
+	render.RenderList(w, r, NewProviderListResponse())
+}
 
+// ProviderStatus lists the circuit breaker state of the providers
+func ProviderStatus(w http.ResponseWriter, r *http.Request) {
+	render.RenderList(w, r, NewStatusListResponse(providers.Circuits))
 }
 
 // Loopback redirects the client to another path on our router
@@ -11,11 +11,29 @@ type Post struct {
 
 	Author    User   `json:"author"`
 	Contents  string `json:"contents"`
+	Lang      string `json:"lang,omitempty"`
 	NumShares int32  `json:"num_shares"`
 	NumLikes  int32  `json:"num_likes"`
 
-	Tags  []string `json:"tags"`
-	Links []string `json:"links"`
+	// Tags are the hashtags of the post, without their #, and cashtags,
+	// with their $. Mentions are the usernames mentioned, without their @.
+	Tags     []string `json:"tags"`
+	Mentions []string `json:"mentions,omitempty"`
+	Links    []string `json:"links"`
+	Media    []*Media `json:"media,omitempty"`
+
+	// Entities are the tags, mentions and links of the contents
+	Entities []Entity `json:"entities,omitempty"`
+
+	// ParentID is the id of the post replied to, and Replies the replies
+	// of a post in a conversation tree
+	ParentID string `json:"parent_id,omitempty"`
+	Replies  Posts  `json:"replies,omitempty"`
+
+	// SharedPost is the original post of a share, ie. a retweet, and
+	// QuotedPost the post quoted by the post
+	SharedPost *Post `json:"shared_post,omitempty"`
+	QuotedPost *Post `json:"quoted_post,omitempty"`
 
 	PublishedAt *time.Time `json:"published_at,omitempty"`
 	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
@@ -28,3 +46,52 @@ type Posts []*Post
 func (ps *Posts) Add(post ...*Post) {
 	*ps = append(*ps, post...)
 }
+
+type MediaType string
+
+const (
+	MediaImage MediaType = "image"
+	MediaVideo MediaType = "video"
+	MediaGIF   MediaType = "gif" // animated gif, usually served as a looping video
+)
+
+// Media is a normalized image, video or gif attached to a post
+type Media struct {
+	Type MediaType `json:"type"`
+
+	// URL of the image, or of the video file
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+
+	AltText      string `json:"alt_text,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"` // preview image of videos and gifs
+}
+
+type EntityType string
+
+const (
+	EntityHashtag EntityType = "hashtag"
+	EntityCashtag EntityType = "cashtag"
+	EntityMention EntityType = "mention"
+	EntityURL     EntityType = "url"
+)
+
+// Entity is a hashtag, cashtag, @mention or link of a post contents, at the
+// [Start, End) code point offsets, as twitter counts them
+type Entity struct {
+	Type  EntityType `json:"type"`
+	Start int        `json:"start"`
+	End   int        `json:"end"`
+
+	// Value is the tag or username, without its prefix, or the url as in
+	// the contents
+	Value string `json:"value"`
+
+	// ExpandedURL and DisplayURL of the shortened links, ie. t.co links
+	ExpandedURL string `json:"expanded_url,omitempty"`
+	DisplayURL  string `json:"display_url,omitempty"`
+
+	// Media is set for the links to the post Media
+	Media bool `json:"media,omitempty"`
+}
@@ -0,0 +1,395 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // calls go through
+	CircuitOpen                         // calls fail fast with ErrCircuitOpen
+	CircuitHalfOpen                     // trial calls go through
+)
+
+var circuitStateNames = map[CircuitState]string{
+	CircuitClosed:   "closed",
+	CircuitOpen:     "open",
+	CircuitHalfOpen: "half-open",
+}
+
+func (s CircuitState) String() string {
+	return circuitStateNames[s]
+}
+
+func (s CircuitState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// CircuitOptions configures when circuit breakers trip and recover
+type CircuitOptions struct {
+	Window      time.Duration // failure rate window, default: 1 minute
+	MinCalls    int           // calls in the window before tripping, default: 10
+	FailureRate float64       // failure rate tripping the circuit, default: 0.5
+
+	// OpenTimeout is how long a tripped circuit fails fast, before letting
+	// HalfOpenCalls trial calls through. Default: 30 seconds, 1 call.
+	OpenTimeout   time.Duration
+	HalfOpenCalls int
+
+	// PerEndpoint keys the circuits by provider id and session method,
+	// instead of by provider id only
+	PerEndpoint bool
+}
+
+// CircuitBreaker fails the calls to a provider fast while it's down,
+// instead of having each call wait for a timeout. It trips when the rate
+// of provider failures, ie. ErrProviderDown, 5xx statuses or network
+// errors, reaches FailureRate, and half-opens after OpenTimeout.
+type CircuitBreaker struct {
+	opts CircuitOptions
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	calls       int
+	failures    int
+	openedAt    time.Time
+	trials      int // calls let through while half-open
+}
+
+func NewCircuitBreaker(opts CircuitOptions) *CircuitBreaker {
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.MinCalls <= 0 {
+		opts.MinCalls = 10
+	}
+	if opts.FailureRate <= 0 {
+		opts.FailureRate = 0.5
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+	if opts.HalfOpenCalls <= 0 {
+		opts.HalfOpenCalls = 1
+	}
+	return &CircuitBreaker{opts: opts}
+}
+
+// State returns the current state of the circuit
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.halfOpen(time.Now())
+	return b.state
+}
+
+// RetryAt returns when an open circuit lets trial calls through
+func (b *CircuitBreaker) RetryAt() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != CircuitOpen {
+		return time.Time{}
+	}
+	return b.openedAt.Add(b.opts.OpenTimeout)
+}
+
+// Allow returns ErrCircuitOpen when the call should fail fast, otherwise
+// the call goes through and its result must be passed to Record
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.halfOpen(now)
+
+	switch b.state {
+	case CircuitOpen:
+		return ErrCircuitOpen.RetryAt(b.openedAt.Add(b.opts.OpenTimeout))
+	case CircuitHalfOpen:
+		if b.trials >= b.opts.HalfOpenCalls {
+			return ErrCircuitOpen.RetryAt(now.Add(b.opts.OpenTimeout))
+		}
+		b.trials++
+	}
+	return nil
+}
+
+// Record records the result of a call let through by Allow
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	failed := isProviderFailure(err)
+
+	if b.state == CircuitHalfOpen {
+		if failed {
+			b.trip(now)
+		} else {
+			b.reset(now)
+		}
+		return
+	}
+	if b.state == CircuitOpen {
+		return
+	}
+
+	if now.Sub(b.windowStart) > b.opts.Window {
+		b.reset(now)
+	}
+	b.calls++
+	if failed {
+		b.failures++
+	}
+	if b.calls >= b.opts.MinCalls && float64(b.failures)/float64(b.calls) >= b.opts.FailureRate {
+		b.trip(now)
+	}
+}
+
+func (b *CircuitBreaker) halfOpen(now time.Time) {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.opts.OpenTimeout {
+		b.state = CircuitHalfOpen
+		b.trials = 0
+	}
+}
+
+func (b *CircuitBreaker) trip(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+}
+
+func (b *CircuitBreaker) reset(now time.Time) {
+	b.state = CircuitClosed
+	b.windowStart = now
+	b.calls = 0
+	b.failures = 0
+}
+
+// isProviderFailure reports whether err tells the provider is down, as
+// opposed to the call being invalid or rate limited
+func isProviderFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrProviderDown) {
+		return true
+	}
+	var e *Error
+	if errors.As(err, &e) && e.HTTPStatus >= 500 {
+		return true
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	// Timeouts are what an outage looks like the most
+	var nerr net.Error
+	return errors.As(err, &nerr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// CircuitBreakers holds the circuit breakers of the providers
+type CircuitBreakers struct {
+	Options CircuitOptions
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker // by provider id, and endpoint
+}
+
+// Circuits are the circuit breakers of the sessions returned by NewSession
+var Circuits = &CircuitBreakers{}
+
+// Get returns the circuit breaker of the provider endpoint, the endpoint
+// is ignored unless Options.PerEndpoint is set
+func (c *CircuitBreakers) Get(providerID, endpoint string) *CircuitBreaker {
+	key := providerID
+	if c.Options.PerEndpoint && endpoint != "" {
+		key += "/" + endpoint
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.breakers == nil {
+		c.breakers = map[string]*CircuitBreaker{}
+	}
+	b, ok := c.breakers[key]
+	if !ok {
+		b = NewCircuitBreaker(c.Options)
+		c.breakers[key] = b
+	}
+	return b
+}
+
+// CircuitStatus is the state of a provider circuit breaker
+type CircuitStatus struct {
+	Provider string
+	Endpoint string // empty unless keyed per endpoint
+	State    CircuitState
+	RetryAt  time.Time // when an open circuit half-opens
+}
+
+// Status returns the state of the circuit breakers, sorted by provider
+// and endpoint
+func (c *CircuitBreakers) Status() []CircuitStatus {
+	c.mu.Lock()
+	breakers := make(map[string]*CircuitBreaker, len(c.breakers))
+	keys := make([]string, 0, len(c.breakers))
+	for k, b := range c.breakers {
+		breakers[k] = b
+		keys = append(keys, k)
+	}
+	c.mu.Unlock()
+
+	sort.Strings(keys)
+
+	status := make([]CircuitStatus, 0, len(keys))
+	for _, k := range keys {
+		b := breakers[k]
+		s := CircuitStatus{State: b.State(), RetryAt: b.RetryAt()}
+		s.Provider, s.Endpoint, _ = strings.Cut(k, "/")
+		status = append(status, s)
+	}
+	return status
+}
+
+// WithCircuitBreaker returns a session failing fast with ErrCircuitOpen
+// while the provider circuit is open
+func WithCircuitBreaker(s ProviderSession, breakers *CircuitBreakers) ProviderSession {
+	return &breakerSession{ProviderSession: s, breakers: breakers}
+}
+
+type breakerSession struct {
+	ProviderSession
+	breakers *CircuitBreakers
+}
+
+func withBreaker[T any](s *breakerSession, endpoint string, call func() (T, error)) (T, error) {
+	b := s.breakers.Get(s.ID(), endpoint)
+	if err := b.Allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+	res, err := call()
+	b.Record(err)
+	return res, err
+}
+
+func (s *breakerSession) breakerCall(endpoint string, call func() error) error {
+	_, err := withBreaker(s, endpoint, func() (struct{}, error) {
+		return struct{}{}, call()
+	})
+	return err
+}
+
+func (s *breakerSession) breakerPage(endpoint string, fetch func(query Query) (social.Posts, *Cursor, error), query Query) (social.Posts, *Cursor, error) {
+	p, err := withBreaker(s, endpoint, func() (page[social.Posts], error) {
+		posts, cursor, err := fetch(query)
+		return page[social.Posts]{posts, cursor}, err
+	})
+	return p.items, p.cursor, err
+}
+
+func (s *breakerSession) breakerUsers(endpoint string, fetch func(query Query) ([]*social.User, *Cursor, error), query Query) ([]*social.User, *Cursor, error) {
+	p, err := withBreaker(s, endpoint, func() (page[[]*social.User], error) {
+		users, cursor, err := fetch(query)
+		return page[[]*social.User]{users, cursor}, err
+	})
+	return p.items, p.cursor, err
+}
+
+func (s *breakerSession) Publish(ctx context.Context, req *PostRequest) (*social.Post, error) {
+	return withBreaker(s, "post", func() (*social.Post, error) {
+		return s.ProviderSession.Publish(ctx, req)
+	})
+}
+
+func (s *breakerSession) DeletePost(ctx context.Context, id string) error {
+	return s.breakerCall("post", func() error {
+		return s.ProviderSession.DeletePost(ctx, id)
+	})
+}
+
+func (s *breakerSession) EditPost(ctx context.Context, id string, req *PostRequest) (*social.Post, error) {
+	return withBreaker(s, "post", func() (*social.Post, error) {
+		return s.ProviderSession.EditPost(ctx, id, req)
+	})
+}
+
+func (s *breakerSession) GetReplies(ctx context.Context, postID string, query Query) (social.Posts, *Cursor, error) {
+	return s.breakerPage("replies", func(query Query) (social.Posts, *Cursor, error) {
+		return s.ProviderSession.GetReplies(ctx, postID, query)
+	}, query)
+}
+
+func (s *breakerSession) GetThread(ctx context.Context, postID string, query Query) (*social.Post, *Cursor, error) {
+	p, err := withBreaker(s, "replies", func() (page[*social.Post], error) {
+		root, cursor, err := s.ProviderSession.GetThread(ctx, postID, query)
+		return page[*social.Post]{root, cursor}, err
+	})
+	return p.items, p.cursor, err
+}
+
+func (s *breakerSession) Like(ctx context.Context, postID string) error {
+	return s.breakerCall("engage", func() error {
+		return s.ProviderSession.Like(ctx, postID)
+	})
+}
+
+func (s *breakerSession) Unlike(ctx context.Context, postID string) error {
+	return s.breakerCall("engage", func() error {
+		return s.ProviderSession.Unlike(ctx, postID)
+	})
+}
+
+func (s *breakerSession) Share(ctx context.Context, postID string) (*social.Post, error) {
+	return withBreaker(s, "engage", func() (*social.Post, error) {
+		return s.ProviderSession.Share(ctx, postID)
+	})
+}
+
+func (s *breakerSession) Follow(ctx context.Context, userID string) error {
+	return s.breakerCall("engage", func() error {
+		return s.ProviderSession.Follow(ctx, userID)
+	})
+}
+
+func (s *breakerSession) Unfollow(ctx context.Context, userID string) error {
+	return s.breakerCall("engage", func() error {
+		return s.ProviderSession.Unfollow(ctx, userID)
+	})
+}
+
+func (s *breakerSession) Search(query Query) (social.Posts, *Cursor, error) {
+	return s.breakerPage("search", s.ProviderSession.Search, query)
+}
+
+func (s *breakerSession) GetFeed(query Query) (social.Posts, *Cursor, error) {
+	return s.breakerPage("feed", s.ProviderSession.GetFeed, query)
+}
+
+func (s *breakerSession) GetPosts(query Query) (social.Posts, *Cursor, error) {
+	return s.breakerPage("posts", s.ProviderSession.GetPosts, query)
+}
+
+func (s *breakerSession) GetUser(query Query) (*social.User, error) {
+	return withBreaker(s, "user", func() (*social.User, error) {
+		return s.ProviderSession.GetUser(query)
+	})
+}
+
+func (s *breakerSession) GetFriends(query Query) ([]*social.User, *Cursor, error) {
+	return s.breakerUsers("friends", s.ProviderSession.GetFriends, query)
+}
+
+func (s *breakerSession) GetFollowers(query Query) ([]*social.User, *Cursor, error) {
+	return s.breakerUsers("followers", s.ProviderSession.GetFollowers, query)
+}
@@ -0,0 +1,160 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := NewCircuitBreaker(CircuitOptions{MinCalls: 4, FailureRate: 0.5, OpenTimeout: 20 * time.Millisecond})
+
+	// Closed until the failure rate is reached over MinCalls
+	for _, err := range []error{nil, ErrProviderDown, nil} {
+		if b.Allow() != nil {
+			t.Fatal("expecting a closed circuit to let calls through")
+		}
+		b.Record(err)
+	}
+	if b.State() != CircuitClosed {
+		t.Fatalf("expecting the circuit closed below MinCalls, got %v", b.State())
+	}
+	b.Allow()
+	b.Record(ErrProviderDown)
+
+	// Open, failing fast until OpenTimeout
+	if b.State() != CircuitOpen {
+		t.Fatalf("expecting the circuit open at 50%% failures, got %v", b.State())
+	}
+	err := b.Allow()
+	var perr *Error
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &perr) || perr.ResetAt.IsZero() || !perr.ResetAt.Equal(b.RetryAt()) {
+		t.Errorf("expecting ErrCircuitOpen with the time it half-opens, got %v", err)
+	}
+
+	// Half-open, letting one trial call through
+	time.Sleep(25 * time.Millisecond)
+	if b.State() != CircuitHalfOpen || !b.RetryAt().IsZero() {
+		t.Fatalf("expecting the circuit half-open after OpenTimeout, got %v", b.State())
+	}
+	if b.Allow() != nil {
+		t.Fatal("expecting a trial call through")
+	}
+	if !errors.Is(b.Allow(), ErrCircuitOpen) {
+		t.Errorf("expecting a single trial call")
+	}
+
+	// A failed trial opens the circuit again
+	b.Record(ErrProviderDown)
+	if b.State() != CircuitOpen {
+		t.Fatalf("expecting a failed trial to open the circuit, got %v", b.State())
+	}
+
+	// And a successful one closes it, with the failures forgotten
+	time.Sleep(25 * time.Millisecond)
+	b.Allow()
+	b.Record(nil)
+	if b.State() != CircuitClosed {
+		t.Fatalf("expecting a successful trial to close the circuit, got %v", b.State())
+	}
+	for i := 0; i < 3; i++ {
+		b.Allow()
+		b.Record(ErrProviderDown)
+	}
+	if b.State() != CircuitClosed {
+		t.Errorf("expecting the calls counted again from the reset, got %v", b.State())
+	}
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	b := NewCircuitBreaker(CircuitOptions{Window: 20 * time.Millisecond, MinCalls: 2})
+	b.Allow()
+	b.Record(ErrProviderDown)
+	time.Sleep(25 * time.Millisecond)
+	b.Allow()
+	b.Record(ErrProviderDown)
+	if b.State() != CircuitClosed {
+		t.Errorf("expecting the failures of a past window to be forgotten, got %v", b.State())
+	}
+}
+
+// endpointSession is a session whose searches fail, and feeds succeed
+type endpointSession struct {
+	ProviderSession
+	calls int
+}
+
+func (s *endpointSession) ID() string {
+	return "endpoints"
+}
+
+func (s *endpointSession) Search(query Query) (social.Posts, *Cursor, error) {
+	s.calls++
+	return nil, nil, ErrProviderDown
+}
+
+func (s *endpointSession) GetFeed(query Query) (social.Posts, *Cursor, error) {
+	s.calls++
+	return social.Posts{{ID: "1"}}, nil, nil
+}
+
+func TestCircuitBreakersPerEndpoint(t *testing.T) {
+	for _, perEndpoint := range []bool{false, true} {
+		breakers := &CircuitBreakers{Options: CircuitOptions{MinCalls: 2, PerEndpoint: perEndpoint}}
+		inner := &endpointSession{}
+		s := WithCircuitBreaker(inner, breakers)
+
+		s.Search(Query{})
+		s.Search(Query{})
+		if _, _, err := s.Search(Query{}); !errors.Is(err, ErrCircuitOpen) || inner.calls != 2 {
+			t.Fatalf("expecting the search circuit open after 2 failures, got %d calls, %v", inner.calls, err)
+		}
+
+		// The feed shares the circuit of the provider, unless keyed per
+		// endpoint
+		_, _, err := s.GetFeed(Query{})
+		if perEndpoint && err != nil {
+			t.Errorf("expecting the feed circuit to be closed, got %v", err)
+		}
+		if !perEndpoint && !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("expecting the provider circuit to be open, got %v", err)
+		}
+
+		status := breakers.Status()
+		if perEndpoint {
+			if len(status) != 2 || status[0].Endpoint != "feed" || status[0].State != CircuitClosed ||
+				status[1].Endpoint != "search" || status[1].State != CircuitOpen || status[1].Provider != "endpoints" {
+				t.Errorf("unexpected status per endpoint %+v", status)
+			}
+		} else if len(status) != 1 || status[0].Provider != "endpoints" || status[0].Endpoint != "" || status[0].State != CircuitOpen {
+			t.Errorf("unexpected status %+v", status)
+		}
+	}
+}
+
+func TestIsProviderFailure(t *testing.T) {
+	tests := map[error]bool{
+		nil:             false,
+		ErrProviderDown: true,
+		fmt.Errorf("wrapped: %w", ErrProviderDown): true,
+		ErrUnknown.ProviderErr(nil, 0, 503):        true,
+		ErrUnknown.ProviderErr(nil, 0, 404):        false,
+		ErrHitRateLimit.ProviderErr(nil, 88, 429):  false,
+		ErrInvalidQuery:          false,
+		ErrPostNotFound:          false,
+		context.Canceled:         false,
+		context.DeadlineExceeded: true,
+		&net.OpError{Op: "dial", Err: errors.New("")}: true,
+		errors.New("failed"):                          false,
+	}
+	for err, want := range tests {
+		if got := isProviderFailure(err); got != want {
+			t.Errorf("%v: expecting %v, got %v", err, want, got)
+		}
+	}
+}
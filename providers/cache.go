@@ -0,0 +1,297 @@
+package providers
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+// Cache is a cache backend of session results, see WithCache
+type Cache interface {
+	// Get returns the entry of the key, expired or not
+	Get(key string) (*CacheEntry, bool)
+
+	// Set stores the entry of the key. Entries should be kept past their
+	// expiry for a while, to be served stale when the provider fails.
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheEntry is a cached session result. Entries are plain data, so out of
+// process backends may encode them, ie. with encoding/json, at the cost of
+// the type of the posts Raw data.
+type CacheEntry struct {
+	Posts     social.Posts   `json:"posts,omitempty"`
+	Users     []*social.User `json:"users,omitempty"`
+	User      *social.User   `json:"user,omitempty"`
+	Post      *social.Post   `json:"post,omitempty"` // thread root
+	Next      url.Values     `json:"next,omitempty"` // args of the cursor queries
+	Prev      url.Values     `json:"prev,omitempty"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// Expired reports whether the entry is stale
+func (e *CacheEntry) Expired() bool {
+	return !time.Now().Before(e.ExpiresAt)
+}
+
+// setCursor stores the args of the cursor queries, without the cursor
+// token of the page they were parsed from, which would take over their
+// ids when parsed back
+func (e *CacheEntry) setCursor(cursor *Cursor) {
+	if cursor == nil {
+		return
+	}
+	if cursor.Next != nil {
+		e.Next = cursorArgs(*cursor.Next)
+	}
+	if cursor.Prev != nil {
+		e.Prev = cursorArgs(*cursor.Prev)
+	}
+}
+
+func (e *CacheEntry) cursor() *Cursor {
+	if e.Next == nil && e.Prev == nil {
+		return nil
+	}
+	c := &Cursor{}
+	if e.Next != nil {
+		next := NewQuery(e.Next)
+		c.Next = &next
+	}
+	if e.Prev != nil {
+		prev := NewQuery(e.Prev)
+		c.Prev = &prev
+	}
+	return c
+}
+
+// CacheTTLs are how long the results of each session method are cached,
+// results of methods with a zero TTL aren't cached
+type CacheTTLs struct {
+	Search    time.Duration
+	Feed      time.Duration
+	Posts     time.Duration
+	User      time.Duration
+	Friends   time.Duration
+	Followers time.Duration
+	Replies   time.Duration // GetReplies and GetThread
+}
+
+var DefaultCacheTTLs = CacheTTLs{
+	Search:    time.Minute,
+	User:      10 * time.Minute,
+	Friends:   5 * time.Minute,
+	Followers: 5 * time.Minute,
+	Replies:   time.Minute,
+}
+
+// WithCache returns a session caching the results of its read methods,
+// keyed by provider, credentials user, method and normalized query.
+//
+// Expired results are served stale when the provider call fails with
+// ErrHitRateLimit, ErrProviderDown or ErrCircuitOpen.
+func WithCache(s ProviderSession, cache Cache, ttls CacheTTLs) ProviderSession {
+	return &cacheSession{ProviderSession: s, cache: cache, ttls: ttls}
+}
+
+type cacheSession struct {
+	ProviderSession
+	cache Cache
+	ttls  CacheTTLs
+}
+
+// cacheKey returns the key of the session method results for the query.
+// The query Params are left out, as the providers only use the query
+// fields.
+func (s *cacheSession) cacheKey(method string, query Query) string {
+	user := ""
+	if creds := s.Credentials(); creds != nil {
+		user = creds.ProviderUserID()
+		if user == "" {
+			h := sha256.Sum256([]byte(creds.AccessToken()))
+			user = hex.EncodeToString(h[:8])
+		}
+	}
+	query.Params = nil
+	return s.ID() + "/" + user + "/" + method + "?" + query.ToURLArgs().Encode()
+}
+
+// cached returns the cached entry of the key, or fetches and caches it.
+// Expired entries are returned when fetch fails with a provider error.
+func (s *cacheSession) cached(key string, ttl time.Duration, fetch func() (*CacheEntry, error)) (*CacheEntry, error) {
+	if ttl <= 0 {
+		return fetch()
+	}
+
+	cached, ok := s.cache.Get(key)
+	if ok && !cached.Expired() {
+		return cached, nil
+	}
+
+	entry, err := fetch()
+	if err != nil {
+		if ok && serveStale(err) {
+			return cached, nil
+		}
+		return nil, err
+	}
+	entry.ExpiresAt = time.Now().Add(ttl)
+	s.cache.Set(key, entry)
+	return entry, nil
+}
+
+func serveStale(err error) bool {
+	return errors.Is(err, ErrHitRateLimit) || errors.Is(err, ErrProviderDown) || errors.Is(err, ErrCircuitOpen)
+}
+
+func (s *cacheSession) cachedPosts(method string, ttl time.Duration, fetch func(query Query) (social.Posts, *Cursor, error), query Query) (social.Posts, *Cursor, error) {
+	entry, err := s.cached(s.cacheKey(method, query), ttl, func() (*CacheEntry, error) {
+		posts, cursor, err := fetch(query)
+		if err != nil {
+			return nil, err
+		}
+		e := &CacheEntry{Posts: posts}
+		e.setCursor(cursor)
+		return e, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry.Posts, entry.cursor(), nil
+}
+
+func (s *cacheSession) cachedUsers(method string, ttl time.Duration, fetch func(query Query) ([]*social.User, *Cursor, error), query Query) ([]*social.User, *Cursor, error) {
+	entry, err := s.cached(s.cacheKey(method, query), ttl, func() (*CacheEntry, error) {
+		users, cursor, err := fetch(query)
+		if err != nil {
+			return nil, err
+		}
+		e := &CacheEntry{Users: users}
+		e.setCursor(cursor)
+		return e, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry.Users, entry.cursor(), nil
+}
+
+func (s *cacheSession) Search(query Query) (social.Posts, *Cursor, error) {
+	return s.cachedPosts("search", s.ttls.Search, s.ProviderSession.Search, query)
+}
+
+func (s *cacheSession) GetFeed(query Query) (social.Posts, *Cursor, error) {
+	return s.cachedPosts("feed", s.ttls.Feed, s.ProviderSession.GetFeed, query)
+}
+
+func (s *cacheSession) GetPosts(query Query) (social.Posts, *Cursor, error) {
+	return s.cachedPosts("posts", s.ttls.Posts, s.ProviderSession.GetPosts, query)
+}
+
+func (s *cacheSession) GetUser(query Query) (*social.User, error) {
+	entry, err := s.cached(s.cacheKey("user", query), s.ttls.User, func() (*CacheEntry, error) {
+		user, err := s.ProviderSession.GetUser(query)
+		if err != nil {
+			return nil, err
+		}
+		return &CacheEntry{User: user}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry.User, nil
+}
+
+func (s *cacheSession) GetReplies(ctx context.Context, postID string, query Query) (social.Posts, *Cursor, error) {
+	return s.cachedPosts("replies/"+postID, s.ttls.Replies, func(query Query) (social.Posts, *Cursor, error) {
+		return s.ProviderSession.GetReplies(ctx, postID, query)
+	}, query)
+}
+
+func (s *cacheSession) GetThread(ctx context.Context, postID string, query Query) (*social.Post, *Cursor, error) {
+	entry, err := s.cached(s.cacheKey("thread/"+postID, query), s.ttls.Replies, func() (*CacheEntry, error) {
+		root, cursor, err := s.ProviderSession.GetThread(ctx, postID, query)
+		if err != nil {
+			return nil, err
+		}
+		e := &CacheEntry{Post: root}
+		e.setCursor(cursor)
+		return e, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry.Post, entry.cursor(), nil
+}
+
+func (s *cacheSession) GetFriends(query Query) ([]*social.User, *Cursor, error) {
+	return s.cachedUsers("friends", s.ttls.Friends, s.ProviderSession.GetFriends, query)
+}
+
+func (s *cacheSession) GetFollowers(query Query) ([]*social.User, *Cursor, error) {
+	return s.cachedUsers("followers", s.ttls.Followers, s.ProviderSession.GetFollowers, query)
+}
+
+// LRUCache is an in-memory Cache, evicting the least recently used entries
+// past its capacity
+type LRUCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+}
+
+type lruItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (c *LRUCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (c *LRUCache) Set(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruItem{key, entry})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.entries, el.Value.(*lruItem).key)
+	}
+}
+
+// Len returns the number of entries in the cache
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
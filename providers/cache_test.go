@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCachePaging(t *testing.T) {
+	ps := newPagedSession("fake", 5)
+	s := bindCursors(WithCache(ps, NewLRUCache(10), CacheTTLs{Search: time.Minute}))
+
+	// Pages through the tokens like a client, the first pass fetching the
+	// pages and the second serving them from the cache
+	search := func() string {
+		var ids []string
+		args := url.Values{"q": {"go"}, "limit": {"2"}}
+		for i := 0; i < 5; i++ {
+			query, err := ParseQuery(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			posts, cursor, err := s.Search(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range posts {
+				ids = append(ids, p.ID)
+			}
+			if !cursor.HasNext() {
+				return strings.Join(ids, ",")
+			}
+			args = url.Values{"cursor": {nextToken(t, cursor)}}
+		}
+		t.Fatalf("expecting the paging to end, got %v", ids)
+		return ""
+	}
+
+	for pass := 1; pass <= 2; pass++ {
+		if got := search(); got != "5,4,3,2,1" {
+			t.Errorf("pass %d: expecting posts 5,4,3,2,1, got %s", pass, got)
+		}
+		if ps.calls != 3 {
+			t.Errorf("pass %d: expecting 3 provider calls, got %d", pass, ps.calls)
+		}
+	}
+}
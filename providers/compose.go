@@ -0,0 +1,292 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-social/social"
+	"golang.org/x/text/unicode/norm"
+)
+
+// PostLimits are the post length limits of a provider, and how it counts
+// characters
+type PostLimits struct {
+	// MaxLength of a post, in weighted characters, or 0 when unlimited
+	MaxLength int
+
+	// URLLength is the length links count for whatever their actual
+	// length, ie. 23 for twitter's t.co links, or 0 to count them as text
+	URLLength int
+
+	// Weight returns the number of characters a character counts for, a
+	// code point or a whole emoji sequence, see chars. All code points
+	// count for one when nil.
+	Weight func(char string) int
+
+	// AppendLink is set when the provider appends the link to the message,
+	// so it counts in its length
+	AppendLink bool
+
+	// MaxImages of a post, or 0 when unlimited, see PrepareMedia
+	MaxImages int
+
+	// MaxMentions and MaxHashtags of a post, or 0 when unlimited
+	MaxMentions int
+	MaxHashtags int
+}
+
+// LimitsOf returns the post limits of a registered provider
+func LimitsOf(providerID string) (PostLimits, error) {
+	r, ok := Registry[providerID]
+	if !ok {
+		return PostLimits{}, ErrUnknownProviderID
+	}
+	return r.Limits, nil
+}
+
+// LimitsOfSession returns the post limits of a session, its provider
+// SessionLimits when set, or its provider Limits
+func LimitsOfSession(ctx context.Context, s ProviderSession) (PostLimits, error) {
+	r, ok := Registry[s.ID()]
+	if !ok {
+		return PostLimits{}, ErrUnknownProviderID
+	}
+	if r.SessionLimits == nil {
+		return r.Limits, nil
+	}
+	return r.SessionLimits(ctx, s)
+}
+
+var (
+	// urlPattern matches the http and www. links of a text, without the
+	// trailing punctuation
+	urlPattern = regexp.MustCompile(`(?:https?://|www\.)\S*[^\s.,;:!?'")\]]`)
+
+	// wordPattern matches the words of a text, with the spaces before them
+	wordPattern = regexp.MustCompile(`\s*\S+`)
+)
+
+// Length returns the weighted length of the text, once NFC normalized
+func (l PostLimits) Length(text string) int {
+	text = norm.NFC.String(text)
+	n, last := 0, 0
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		n += l.textLength(text[last:loc[0]])
+		if l.URLLength > 0 {
+			n += l.URLLength
+		} else {
+			n += l.textLength(text[loc[0]:loc[1]])
+		}
+		last = loc[1]
+	}
+	return n + l.textLength(text[last:])
+}
+
+func (l PostLimits) textLength(text string) int {
+	if l.Weight == nil {
+		return utf8.RuneCountInString(text)
+	}
+	n := 0
+	for _, char := range chars(text) {
+		n += l.Weight(char)
+	}
+	return n
+}
+
+// chars splits the text in characters: code points, except for the emoji
+// sequences which are kept whole, ie. the emojis with a skin tone, the
+// zero width joined ones, the flags and the keycaps
+func chars(text string) []string {
+	var cs []string
+	for text != "" {
+		n := charLen(text)
+		cs = append(cs, text[:n])
+		text = text[n:]
+	}
+	return cs
+}
+
+// charLen returns the length of the first character of the text, see chars
+func charLen(text string) int {
+	first, n := utf8.DecodeRuneInString(text)
+	if isRegionalIndicator(first) {
+		if r, size := utf8.DecodeRuneInString(text[n:]); isRegionalIndicator(r) {
+			return n + size // a flag
+		}
+		return n
+	}
+	for n < len(text) {
+		r, size := utf8.DecodeRuneInString(text[n:])
+		switch {
+		case isEmojiModifier(r):
+			n += size
+		case r == zwj && first > 0x10FF:
+			next, nsize := utf8.DecodeRuneInString(text[n+size:])
+			if next <= 0x10FF {
+				return n
+			}
+			n += size + nsize
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+const zwj = '\u200D' // zero width joiner
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isEmojiModifier reports whether r modifies the emoji before it: variation
+// selectors, skin tones, the keycap and the tags of subdivision flags
+func isEmojiModifier(r rune) bool {
+	return r == 0xFE0E || r == 0xFE0F ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) ||
+		r == 0x20E3 ||
+		(r >= 0xE0020 && r <= 0xE007F)
+}
+
+// Split splits the text in parts fitting MaxLength, between words unless a
+// word is longer than a part. Links are never cut, a link which doesn't fit
+// a part goes to its own part. When there are several parts, they're
+// numbered with a " 1/3" suffix.
+func (l PostLimits) Split(text string) []string {
+	text = strings.TrimSpace(text)
+	if l.MaxLength <= 0 || l.Length(text) <= l.MaxLength {
+		return []string{text}
+	}
+
+	// The numbering length depends on the number of parts
+	for digits := 1; ; digits++ {
+		max := l.MaxLength - len(" /") - 2*digits
+		if max < 1 {
+			return []string{text}
+		}
+		parts := l.split(text, max)
+		if len(strconv.Itoa(len(parts))) > digits {
+			continue
+		}
+		for i := range parts {
+			parts[i] += fmt.Sprintf(" %d/%d", i+1, len(parts))
+		}
+		return parts
+	}
+}
+
+func (l PostLimits) split(text string, max int) []string {
+	var parts []string
+	part := ""
+	for _, word := range wordPattern.FindAllString(text, -1) {
+		if part == "" {
+			word = strings.TrimLeftFunc(word, unicode.IsSpace)
+		}
+		if l.Length(part+word) <= max {
+			part += word
+			continue
+		}
+		if part != "" {
+			parts = append(parts, part)
+			word = strings.TrimLeftFunc(word, unicode.IsSpace)
+		}
+
+		// Words longer than a part are cut
+		for l.Length(word) > max {
+			i := l.cut(word, max)
+			parts = append(parts, word[:i])
+			word = word[i:]
+		}
+		part = word
+	}
+	if part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// cut returns the index of the end of the longest prefix of the text
+// fitting max, of one character at least, or of the whole link the text
+// starts with. The text is never cut inside a link or a character.
+func (l PostLimits) cut(text string, max int) int {
+	links := urlPattern.FindAllStringIndex(text, -1)
+	linkEnd := func(i int) int {
+		for _, loc := range links {
+			if i > loc[0] && i < loc[1] {
+				return loc[1]
+			}
+		}
+		return 0
+	}
+
+	end := charLen(text)
+	if e := linkEnd(end); e > 0 {
+		end = e
+	}
+	for i := charLen(text); i < len(text); i += charLen(text[i:]) {
+		if i <= end || linkEnd(i) > 0 {
+			continue
+		}
+		if l.Length(text[:i]) > max {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+// PublishThread publishes a post too long for the provider as a thread,
+// each part replying to the previous one, see PostLimits.Split, and returns
+// the posts created. The link is appended to the message when the provider
+// appends it, or goes with the first part like the media and quote.
+//
+// When a part fails, the posts published before it are returned with the
+// error, to be deleted or completed.
+func PublishThread(ctx context.Context, s ProviderSession, req *PostRequest) (social.Posts, error) {
+	limits, err := LimitsOfSession(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	text := req.Message
+	if limits.AppendLink && req.Link != "" && !strings.Contains(text, req.Link) {
+		text = strings.TrimSpace(text) + " " + req.Link
+	}
+	parts := limits.Split(text)
+	if len(parts) == 1 {
+		post, err := s.Publish(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return social.Posts{post}, nil
+	}
+	if req.ScheduledAt != nil {
+		return nil, ErrUnsupportedField(s.ID(), "scheduled time of a thread")
+	}
+
+	var posts social.Posts
+	replyTo := req.ReplyTo
+	for i, part := range parts {
+		r := &PostRequest{Message: part, ReplyTo: replyTo, Options: req.Options}
+		if i == 0 {
+			if !limits.AppendLink {
+				r.Link, r.LinkPreview = req.Link, req.LinkPreview
+			}
+			r.Media = req.Media
+			r.QuoteURL = req.QuoteURL
+			r.Visibility = req.Visibility
+		}
+		post, err := s.Publish(ctx, r)
+		if err != nil {
+			return posts, err
+		}
+		posts.Add(post)
+		replyTo = post.ID
+	}
+	return posts, nil
+}
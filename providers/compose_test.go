@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-social/social"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		limits PostLimits
+		text   string
+		want   []string
+	}{
+		// Fits as is, without numbering
+		{PostLimits{MaxLength: 10}, "  hello go  ", []string{"hello go"}},
+		{PostLimits{}, strings.Repeat("a ", 100), []string{strings.TrimSpace(strings.Repeat("a ", 100))}},
+
+		// Between words, the parts fitting MaxLength with " 1/2"
+		{PostLimits{MaxLength: 11}, "aaa bbb cccc", []string{"aaa bbb 1/2", "cccc 2/2"}},
+		{PostLimits{MaxLength: 10}, "aaaaa bbbbb", []string{"aaaaa 1/2", "bbbbb 2/2"}},
+
+		// Words longer than a part are cut
+		{PostLimits{MaxLength: 8}, "abcdefghij", []string{"abcd 1/3", "efgh 2/3", "ij 3/3"}},
+
+		// Numbering over 9 parts takes more room
+		{PostLimits{MaxLength: 7}, "a b c d e f g h i j", []string{
+			"a b 1/5", "c d 2/5", "e f 3/5", "g h 4/5", "i j 5/5",
+		}},
+		{PostLimits{MaxLength: 8}, strings.Repeat("aa ", 10), []string{
+			"aa 1/10", "aa 2/10", "aa 3/10", "aa 4/10", "aa 5/10", "aa 6/10", "aa 7/10", "aa 8/10", "aa 9/10", "aa 10/10",
+		}},
+
+		// Too short for the numbering
+		{PostLimits{MaxLength: 4}, "aa bb", []string{"aa bb"}},
+	}
+	for _, tt := range tests {
+		got := tt.limits.Split(tt.text)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expecting %q, got %q", tt.text, tt.want, got)
+		}
+		for _, part := range got {
+			if tt.limits.MaxLength > 0 && len(got) > 1 && tt.limits.Length(part) > tt.limits.MaxLength {
+				t.Errorf("%q: part %q over %d", tt.text, part, tt.limits.MaxLength)
+			}
+		}
+	}
+}
+
+func TestSplitLinks(t *testing.T) {
+	link := "https://example.com/a/long/path"
+
+	// Links aren't cut, a link longer than a part goes to its own part
+	limits := PostLimits{MaxLength: 20}
+	got := limits.Split("see:" + link + " now")
+	want := []string{"see: 1/3", link + " 2/3", "now 3/3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %q, got %q", want, got)
+	}
+
+	// Links counting for URLLength move to the next part whole
+	limits = PostLimits{MaxLength: 20, URLLength: 10}
+	got = limits.Split("hello there " + link + " ok")
+	want = []string{"hello there 1/2", link + " ok 2/2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %q, got %q", want, got)
+	}
+}
+
+// publishSession is a fake session recording the posts published
+type publishSession struct {
+	ProviderSession
+	id   string
+	reqs []*PostRequest
+}
+
+func (s *publishSession) ID() string {
+	return s.id
+}
+
+func (s *publishSession) Publish(ctx context.Context, req *PostRequest) (*social.Post, error) {
+	s.reqs = append(s.reqs, req)
+	return &social.Post{ID: string(rune('a' + len(s.reqs) - 1)), Contents: req.Message}, nil
+}
+
+func TestPublishThread(t *testing.T) {
+	Registry["thread"] = &Provider{Limits: PostLimits{MaxLength: 12, URLLength: 5, AppendLink: true}}
+	defer delete(Registry, "thread")
+	req := &PostRequest{Message: "aaa bbb ccc ddd", Link: "www.go.dev", ReplyTo: "0"}
+
+	s := &publishSession{id: "thread"}
+	posts, err := PublishThread(context.Background(), s, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 3 || len(s.reqs) != 3 {
+		t.Fatalf("expecting 3 posts, got %d", len(posts))
+	}
+	for i, r := range s.reqs {
+		if r.Link != "" {
+			t.Errorf("part %d: expecting the link in the message, got %q", i, r.Link)
+		}
+	}
+	if s.reqs[2].Message != "www.go.dev 3/3" || s.reqs[0].ReplyTo != "0" || s.reqs[1].ReplyTo != "a" || s.reqs[2].ReplyTo != "b" {
+		t.Errorf("unexpected parts %+v %+v %+v", s.reqs[0], s.reqs[1], s.reqs[2])
+	}
+
+	// Providers attaching the link get it with the first part
+	Registry["thread"].Limits.AppendLink = false
+	s = &publishSession{id: "thread"}
+	if _, err := PublishThread(context.Background(), s, req); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.reqs) != 2 || s.reqs[0].Link != req.Link || s.reqs[1].Link != "" {
+		t.Errorf("expecting the link with the first of 2 parts, got %+v", s.reqs)
+	}
+
+	// Session limits take over the provider ones
+	Registry["thread"].SessionLimits = func(ctx context.Context, s ProviderSession) (PostLimits, error) {
+		return PostLimits{MaxLength: 500}, nil
+	}
+	s = &publishSession{id: "thread"}
+	if _, err := PublishThread(context.Background(), s, req); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.reqs) != 1 || s.reqs[0] != req {
+		t.Errorf("expecting the request published as is, got %+v", s.reqs)
+	}
+}
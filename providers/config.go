@@ -1,6 +1,8 @@
 package providers
 
 import (
+	"crypto/sha256"
+
 	"github.com/go-chi/jwtauth"
 )
 
@@ -14,6 +16,8 @@ type ProviderConfig struct {
 
 type ProviderConfigs map[string]ProviderConfig
 
+// Configure configures the registered providers and the state tokens auth.
+// The CursorKey is derived from the token auth when it isn't set.
 func Configure(confs ProviderConfigs, tokenAuth *jwtauth.JWTAuth) {
 	for id, conf := range confs {
 		if p, ok := Registry[id]; ok {
@@ -21,4 +25,25 @@ func Configure(confs ProviderConfigs, tokenAuth *jwtauth.JWTAuth) {
 		}
 	}
 	TokenAuth = tokenAuth
+
+	if len(CursorKey) == 0 && tokenAuth != nil {
+		CursorKey = deriveCursorKey(tokenAuth)
+	}
+}
+
+// deriveCursorKey returns a key derived from the secret of the token auth,
+// as the hash of a fixed claim it signs, or nil when its signatures aren't
+// the same each time, ie. with ECDSA keys
+func deriveCursorKey(tokenAuth *jwtauth.JWTAuth) []byte {
+	claims := jwtauth.Claims{"sub": "CursorKey"}
+	_, a, err := tokenAuth.Encode(claims)
+	if err != nil || a == "" {
+		return nil
+	}
+	_, b, err := tokenAuth.Encode(claims)
+	if err != nil || a != b {
+		return nil
+	}
+	key := sha256.Sum256([]byte(a))
+	return key[:]
 }
@@ -1,5 +1,42 @@
 package providers
 
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+// CursorKey is the HMAC key signing the cursor tokens, of MinCursorKeyLen
+// bytes at least, the same on all the processes decoding the tokens. When
+// it isn't set, Configure derives it from the state tokens auth. Encoding
+// or decoding a cursor without it fails with ErrNoCursorKey.
+var CursorKey []byte
+
+// MinCursorKeyLen is the minimum length of the CursorKey
+const MinCursorKeyLen = 32
+
+// CursorTTL is how long cursor tokens are valid, or 0 for ever
+var CursorTTL = 24 * time.Hour
+
+const (
+	// cursorProviderKey is the arg binding a cursor token to the provider
+	// it pages, see bindCursors
+	cursorProviderKey = "cursor_provider"
+
+	// cursorExpiresKey is the arg of the unix time a cursor token expires
+	cursorExpiresKey = "cursor_expires"
+)
+
 // Query cursor
 type Cursor struct {
 	Next *Query
@@ -21,3 +58,230 @@ func NewCursor(query Query, prevID string, nextID string) *Cursor {
 
 	return c
 }
+
+// HasNext reports whether there is a next page
+func (c *Cursor) HasNext() bool {
+	return c != nil && c.Next != nil && c.Next.UntilID != ""
+}
+
+// NextToken returns the opaque token of the next page, or an empty
+// string when there is no next page
+func (c *Cursor) NextToken() (string, error) {
+	if !c.HasNext() {
+		return "", nil
+	}
+	return EncodeCursor(*c.Next)
+}
+
+// PrevToken returns the opaque token of the previous page, or an empty
+// string when there is no previous page
+func (c *Cursor) PrevToken() (string, error) {
+	if c == nil || c.Prev == nil || c.Prev.SinceID == "" {
+		return "", nil
+	}
+	return EncodeCursor(*c.Prev)
+}
+
+// MarshalJSON encodes the cursor as opaque tokens, so clients never see
+// the provider-specific pagination params
+func (c *Cursor) MarshalJSON() ([]byte, error) {
+	next, err := c.NextToken()
+	if err != nil {
+		return nil, err
+	}
+	prev, err := c.PrevToken()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Next string `json:"next,omitempty"`
+		Prev string `json:"prev,omitempty"`
+	}{next, prev})
+}
+
+// EncodeCursor returns a signed, opaque token of the query, which is
+// accepted back by NewQuery as the `cursor` argument until CursorTTL
+func EncodeCursor(query Query) (string, error) {
+	return encodeCursorArgs(cursorArgs(query))
+}
+
+// DecodeCursor verifies a cursor token and returns the query args it
+// was encoded from
+func DecodeCursor(token string) (url.Values, error) {
+	payload, err := verifyCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	args, err := url.ParseQuery(string(payload))
+	if err != nil {
+		return nil, ErrInvalidCursor.Err(err)
+	}
+
+	if exp := args.Get(cursorExpiresKey); exp != "" {
+		t, err := strconv.ParseInt(exp, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor.Err(err)
+		}
+		if time.Now().Unix() > t {
+			return nil, ErrInvalidCursor.Err(errors.New("cursor expired"))
+		}
+		args.Del(cursorExpiresKey)
+	}
+	return args, nil
+}
+
+// encodeCursorArgs signs the args, along with their expiry time
+func encodeCursorArgs(args url.Values) (string, error) {
+	if CursorTTL > 0 {
+		args.Set(cursorExpiresKey, strconv.FormatInt(time.Now().Add(CursorTTL).Unix(), 10))
+	}
+	return signCursor([]byte(args.Encode()))
+}
+
+// cursorArgs returns the query args of a cursor query, without the
+// cursor token it may have been parsed from
+func cursorArgs(query Query) url.Values {
+	args := query.ToURLArgs()
+	args.Del("cursor")
+	return args
+}
+
+func signCursor(payload []byte) (string, error) {
+	mac, err := cursorMAC(payload)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(mac), nil
+}
+
+func verifyCursor(token string) ([]byte, error) {
+	enc := base64.RawURLEncoding
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor.Err(err)
+	}
+	mac, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor.Err(err)
+	}
+	want, err := cursorMAC(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, want) {
+		return nil, ErrInvalidCursor
+	}
+	return payload, nil
+}
+
+func cursorMAC(payload []byte) ([]byte, error) {
+	if len(CursorKey) < MinCursorKeyLen {
+		return nil, ErrNoCursorKey.Err(fmt.Errorf("CursorKey must be set to %d bytes at least", MinCursorKeyLen))
+	}
+	h := hmac.New(sha256.New, CursorKey)
+	h.Write(payload)
+	return h.Sum(nil), nil
+}
+
+// bindCursors binds the cursor tokens of the session to its provider, so
+// the tokens of a provider can't be replayed on another: the cursors
+// returned are stamped with the provider id, and the queries paging with
+// a token of another provider fail with ErrInvalidCursor
+func bindCursors(s ProviderSession) ProviderSession {
+	return &cursorSession{s}
+}
+
+type cursorSession struct {
+	ProviderSession
+}
+
+// check verifies the query cursor token was issued by the provider
+func (s *cursorSession) check(query Query) error {
+	if query.Params.Get("cursor") == "" {
+		return nil
+	}
+	if id := query.Params.Get(cursorProviderKey); id != s.ID() {
+		return ErrInvalidCursor.Err(fmt.Errorf("cursor of provider %q used on %q", id, s.ID()))
+	}
+	return nil
+}
+
+// bind returns a copy of the cursor stamped with the provider id, as
+// cursors may be shared, ie. cached
+func (s *cursorSession) bind(cursor *Cursor) *Cursor {
+	if cursor == nil {
+		return nil
+	}
+	stamp := func(q *Query) *Query {
+		if q == nil {
+			return nil
+		}
+		c := *q
+		c.Params = cloneParams(q.Params)
+		c.Params.Set(cursorProviderKey, s.ID())
+		return &c
+	}
+	return &Cursor{Next: stamp(cursor.Next), Prev: stamp(cursor.Prev)}
+}
+
+func (s *cursorSession) Search(query Query) (social.Posts, *Cursor, error) {
+	if err := s.check(query); err != nil {
+		return nil, nil, err
+	}
+	posts, cursor, err := s.ProviderSession.Search(query)
+	return posts, s.bind(cursor), err
+}
+
+func (s *cursorSession) GetFeed(query Query) (social.Posts, *Cursor, error) {
+	if err := s.check(query); err != nil {
+		return nil, nil, err
+	}
+	posts, cursor, err := s.ProviderSession.GetFeed(query)
+	return posts, s.bind(cursor), err
+}
+
+func (s *cursorSession) GetPosts(query Query) (social.Posts, *Cursor, error) {
+	if err := s.check(query); err != nil {
+		return nil, nil, err
+	}
+	posts, cursor, err := s.ProviderSession.GetPosts(query)
+	return posts, s.bind(cursor), err
+}
+
+func (s *cursorSession) GetFriends(query Query) ([]*social.User, *Cursor, error) {
+	if err := s.check(query); err != nil {
+		return nil, nil, err
+	}
+	users, cursor, err := s.ProviderSession.GetFriends(query)
+	return users, s.bind(cursor), err
+}
+
+func (s *cursorSession) GetFollowers(query Query) ([]*social.User, *Cursor, error) {
+	if err := s.check(query); err != nil {
+		return nil, nil, err
+	}
+	users, cursor, err := s.ProviderSession.GetFollowers(query)
+	return users, s.bind(cursor), err
+}
+
+func (s *cursorSession) GetReplies(ctx context.Context, postID string, query Query) (social.Posts, *Cursor, error) {
+	if err := s.check(query); err != nil {
+		return nil, nil, err
+	}
+	posts, cursor, err := s.ProviderSession.GetReplies(ctx, postID, query)
+	return posts, s.bind(cursor), err
+}
+
+func (s *cursorSession) GetThread(ctx context.Context, postID string, query Query) (*social.Post, *Cursor, error) {
+	if err := s.check(query); err != nil {
+		return nil, nil, err
+	}
+	post, cursor, err := s.ProviderSession.GetThread(ctx, postID, query)
+	return post, s.bind(cursor), err
+}
@@ -0,0 +1,162 @@
+package providers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth"
+)
+
+func TestCursorSigning(t *testing.T) {
+	token := encodeCursor(t, Query{Limit: 10, UntilID: "42"})
+
+	args, err := DecodeCursor(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Get("until_id") != "42" || args.Get("limit") != "10" || args.Get(cursorExpiresKey) != "" {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	key := CursorKey
+	defer func() { CursorKey = key }()
+	CursorKey = []byte(strings.Repeat("k", MinCursorKeyLen))
+	if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expecting a token signed with another key to be invalid, got %v", err)
+	}
+}
+
+func TestCursorTampering(t *testing.T) {
+	token := encodeCursor(t, Query{Limit: 10, UntilID: "42"})
+	payload, mac, _ := strings.Cut(token, ".")
+
+	forged := url.Values{"limit": {"10"}, "until_id": {"1"}}
+	tests := []string{
+		"",
+		payload,
+		payload + ".",
+		"." + mac,
+		payload + "." + mac + "." + mac,
+		payload + "x." + mac,
+		payload + "." + mac[1:],
+		base64.RawURLEncoding.EncodeToString([]byte(forged.Encode())) + "." + mac,
+	}
+	for _, tok := range tests {
+		if _, err := DecodeCursor(tok); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%q: expecting ErrInvalidCursor, got %v", tok, err)
+		}
+	}
+}
+
+func TestCursorExpiry(t *testing.T) {
+	args := url.Values{"until_id": {"42"}}
+
+	args.Set(cursorExpiresKey, strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))
+	if _, err := DecodeCursor(sign(t, args)); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expecting an expired cursor to be invalid, got %v", err)
+	}
+
+	args.Set(cursorExpiresKey, strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	if _, err := DecodeCursor(sign(t, args)); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	ttl := CursorTTL
+	defer func() { CursorTTL = ttl }()
+	CursorTTL = 0
+	payload, _, _ := strings.Cut(encodeCursor(t, Query{UntilID: "42"}), ".")
+	if strings.Contains(payload, cursorExpiresKey) {
+		t.Errorf("expecting no expiry without a CursorTTL")
+	}
+}
+
+func TestCursorKeyRequired(t *testing.T) {
+	token := encodeCursor(t, Query{UntilID: "42"})
+
+	key := CursorKey
+	defer func() { CursorKey = key }()
+	CursorKey = nil
+
+	if _, err := EncodeCursor(Query{UntilID: "42"}); !errors.Is(err, ErrNoCursorKey) {
+		t.Errorf("expecting ErrNoCursorKey encoding, got %v", err)
+	}
+	if _, err := (&Cursor{Next: &Query{UntilID: "42"}}).MarshalJSON(); !errors.Is(err, ErrNoCursorKey) {
+		t.Errorf("expecting ErrNoCursorKey marshaling, got %v", err)
+	}
+	if _, err := ParseQuery(url.Values{"cursor": {token}}); !errors.Is(err, ErrNoCursorKey) {
+		t.Errorf("expecting ErrNoCursorKey decoding, got %v", err)
+	}
+}
+
+func TestConfigureCursorKey(t *testing.T) {
+	key, tokenAuth := CursorKey, TokenAuth
+	defer func() { CursorKey, TokenAuth = key, tokenAuth }()
+
+	// Derived from the token auth, the same for the same secret
+	CursorKey = nil
+	Configure(nil, jwtauth.New("HS256", []byte("secret"), nil))
+	derived := CursorKey
+	if len(derived) < MinCursorKeyLen {
+		t.Fatalf("expecting a derived CursorKey, got %d bytes", len(derived))
+	}
+	CursorKey = nil
+	Configure(nil, jwtauth.New("HS256", []byte("secret"), nil))
+	if string(CursorKey) != string(derived) {
+		t.Errorf("expecting the same key for the same secret")
+	}
+	CursorKey = nil
+	Configure(nil, jwtauth.New("HS256", []byte("other"), nil))
+	if string(CursorKey) == string(derived) {
+		t.Errorf("expecting another key for another secret")
+	}
+
+	// A key set is kept
+	CursorKey = []byte(strings.Repeat("k", MinCursorKeyLen))
+	Configure(nil, jwtauth.New("HS256", []byte("secret"), nil))
+	if string(CursorKey) != strings.Repeat("k", MinCursorKeyLen) {
+		t.Errorf("expecting the CursorKey set to be kept")
+	}
+}
+
+// sign returns the cursor token of the args, without an expiry
+func sign(t *testing.T, args url.Values) string {
+	t.Helper()
+	token, err := signCursor([]byte(args.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestCursorProviderBinding(t *testing.T) {
+	a := bindCursors(newPagedSession("a", 5))
+	b := bindCursors(newPagedSession("b", 5))
+
+	_, cursor, err := a.Search(Query{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, err := ParseQuery(url.Values{"cursor": {nextToken(t, cursor)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := a.Search(query); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, _, err := b.Search(query); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expecting a cursor of another provider to be invalid, got %v", err)
+	}
+
+	// Tokens of unbound sessions aren't accepted either
+	_, cursor, _ = newPagedSession("a", 5).Search(Query{Limit: 2})
+	query, _ = ParseQuery(url.Values{"cursor": {nextToken(t, cursor)}})
+	if _, _, err := a.Search(query); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expecting an unbound cursor to be invalid, got %v", err)
+	}
+}
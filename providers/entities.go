@@ -0,0 +1,138 @@
+package providers
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-social/social"
+)
+
+// The entity patterns follow the twitter-text rules, their first group is
+// the character before the entity, as go regexps can't look behind: for
+// mentions, also a "RT" or "RT:" retweet prefix, and for hashtags, an emoji
+// variation selector
+var (
+	mentionPattern = regexp.MustCompile(`(^|[^\w!@#$%&*＠]|(?:^|[^\w+~.-])[Rr][Tt]:?)[@＠](\w{1,20})`)
+	hashtagPattern = regexp.MustCompile(`(^|[\x{FE0E}\x{FE0F}]|[^\p{L}\p{M}\p{N}_&#＃])[#＃]([\p{L}\p{M}\p{N}_]*[\p{L}\p{M}][\p{L}\p{M}\p{N}_]*)`)
+	cashtagPattern = regexp.MustCompile(`(^|\s)\$([A-Za-z]{1,6}(?:[._][A-Za-z]{1,2})?)`)
+)
+
+// URLExpansion is the expanded url of a shortened link, ie. of a t.co link
+type URLExpansion struct {
+	ExpandedURL string
+	DisplayURL  string
+
+	// Media is set for the links to the post media, which are left out of
+	// the post Links
+	Media bool
+}
+
+// ExtractEntities returns the links, @mentions, #hashtags and $cashtags of
+// a text, ordered by offset. Entities within links are left out.
+func ExtractEntities(text string) []social.Entity {
+	type span struct {
+		entity     social.Entity
+		start, end int // byte offsets
+	}
+	var spans []span
+	overlaps := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		spans = append(spans, span{social.Entity{Type: social.EntityURL, Value: text[loc[0]:loc[1]]}, loc[0], loc[1]})
+	}
+
+	patterns := []struct {
+		kind    social.EntityType
+		pattern *regexp.Regexp
+		invalid func(value, after string) bool
+	}{
+		{social.EntityMention, mentionPattern, func(value, after string) bool {
+			return strings.HasPrefix(after, "@") || strings.HasPrefix(after, "＠") || strings.HasPrefix(after, "://")
+		}},
+		{social.EntityHashtag, hashtagPattern, func(value, after string) bool {
+			// A "#️⃣" keycap emoji isn't a hashtag
+			if strings.HasPrefix(value, "\uFE0F") || strings.HasPrefix(value, "\u20E3") {
+				return true
+			}
+			return strings.HasPrefix(after, "#") || strings.HasPrefix(after, "＃") || strings.HasPrefix(after, "://")
+		}},
+		{social.EntityCashtag, cashtagPattern, func(value, after string) bool {
+			r, _ := utf8.DecodeRuneInString(after)
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}},
+	}
+	for _, p := range patterns {
+		for _, m := range p.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[3], m[5] // after the preceding character, to the end of the value
+			if p.invalid(text[m[4]:m[5]], text[end:]) || overlaps(start, end) {
+				continue
+			}
+			spans = append(spans, span{social.Entity{Type: p.kind, Value: text[m[4]:m[5]]}, start, end})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	// Byte to code point offsets
+	entities := make([]social.Entity, len(spans))
+	offset, last := 0, 0
+	for i, s := range spans {
+		offset += utf8.RuneCountInString(text[last:s.start])
+		e := s.entity
+		e.Start = offset
+		e.End = offset + utf8.RuneCountInString(text[s.start:s.end])
+		entities[i] = e
+		offset, last = e.End, s.end
+	}
+	return entities
+}
+
+// AddEntities sets the post Entities to the entities of its contents, with
+// the shortened links expanded by urls, which may be nil, and fills in the
+// Tags, Mentions and Links. Tags and links already set are kept.
+func AddEntities(post *social.Post, urls map[string]URLExpansion) {
+	post.Entities = ExtractEntities(post.Contents)
+	for i := range post.Entities {
+		e := &post.Entities[i]
+		switch e.Type {
+		case social.EntityHashtag:
+			post.Tags = appendUnique(post.Tags, e.Value)
+		case social.EntityCashtag:
+			post.Tags = appendUnique(post.Tags, "$"+e.Value)
+		case social.EntityMention:
+			post.Mentions = appendUnique(post.Mentions, e.Value)
+		case social.EntityURL:
+			if x, ok := urls[e.Value]; ok {
+				e.ExpandedURL, e.DisplayURL, e.Media = x.ExpandedURL, x.DisplayURL, x.Media
+			}
+			if e.Media {
+				continue
+			}
+			link := e.Value
+			if e.ExpandedURL != "" {
+				link = e.ExpandedURL
+			}
+			post.Links = appendUnique(post.Links, link)
+		}
+	}
+}
+
+// appendUnique appends the value when it's not in the list yet
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
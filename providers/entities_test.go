@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-social/social"
+)
+
+// values returns the "type:value" of the entities
+func values(entities []social.Entity) []string {
+	var vs []string
+	for _, e := range entities {
+		vs = append(vs, string(e.Type)+":"+e.Value)
+	}
+	return vs
+}
+
+// The cases follow the twitter-text extraction conformance tests
+func TestExtractEntities(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		// Mentions
+		{"@username", []string{"mention:username"}},
+		{"hello @username!", []string{"mention:username"}},
+		{"@user1 @user2", []string{"mention:user1", "mention:user2"}},
+		{"＠username fullwidth", []string{"mention:username"}},
+		{"@username.name", []string{"mention:username"}},
+		{"email@domain.com", nil},
+		{"me+tag@domain.com", nil},
+		{"foo!@bar #@baz", nil},
+		{"@user@domain", nil},
+		{"@user://x", nil},
+		{"RT@username", []string{"mention:username"}},
+		{"RT:@username", []string{"mention:username"}},
+		{"DART@username", nil},
+
+		// Hashtags
+		{"#hashtag", []string{"hashtag:hashtag"}},
+		{"＃hashtag fullwidth", []string{"hashtag:hashtag"}},
+		{"a ＃日本語 b", []string{"hashtag:日本語"}},
+		{"#hash_tag #tag1 #1tag", []string{"hashtag:hash_tag", "hashtag:tag1", "hashtag:1tag"}},
+		{"#1 #123 #1_ #_", nil},
+		{"#_tag", []string{"hashtag:_tag"}},
+		{"a#hashtag", nil},
+		{"&#nbsp; #hash#tag", nil},
+		{"#hashtag://x", nil},
+		{"#️⃣ keycap", nil},
+		{"❤️#love", []string{"hashtag:love"}},
+
+		// Links, with the fragments and @ paths left out
+		{"https://example.com/#anchor", []string{"url:https://example.com/#anchor"}},
+		{"see www.example.com/page#section.", []string{"url:www.example.com/page#section"}},
+		{"https://example.com/@user and #tag", []string{"url:https://example.com/@user", "hashtag:tag"}},
+
+		// Cashtags
+		{"$TEST $Stock $symbol", []string{"cashtag:TEST", "cashtag:Stock", "cashtag:symbol"}},
+		{"$ZZ.N $AAPL_B", []string{"cashtag:ZZ.N", "cashtag:AAPL_B"}},
+		{"$test. $test_,", []string{"cashtag:test", "cashtag:test"}},
+		{"$AAPL, $MSFT! ($GOOG) $TSLA.", []string{"cashtag:AAPL", "cashtag:MSFT", "cashtag:TSLA"}},
+		{"$123 $test123 $TE123ST $toolongone", nil},
+		{"$ストック $privé", nil},
+		{"a$TEST", nil},
+	}
+	for _, tt := range tests {
+		if got := values(ExtractEntities(tt.text)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expecting %q, got %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestExtractEntitiesOffsets(t *testing.T) {
+	// Code point offsets, as twitter counts them
+	entities := ExtractEntities("日本 @user #タグ https://go.dev")
+	want := []social.Entity{
+		{Type: social.EntityMention, Value: "user", Start: 3, End: 8},
+		{Type: social.EntityHashtag, Value: "タグ", Start: 9, End: 12},
+		{Type: social.EntityURL, Value: "https://go.dev", Start: 13, End: 27},
+	}
+	if !reflect.DeepEqual(entities, want) {
+		t.Errorf("expecting %+v, got %+v", want, entities)
+	}
+}
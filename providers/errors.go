@@ -1,6 +1,10 @@
 package providers
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // TODO: redo the errors.. use pkg/errors
 // review box errors thing or other stuff in render..
@@ -28,6 +32,10 @@ var (
 	ErrDuplicatePost     = &Error{Code: 2004, Msg: "duplicate post"}
 	ErrUsernameSearch    = &Error{Code: 2005, Msg: "provided doesn't allow @username searches, @page (brand) searches work"}
 	ErrUnauthorizedQuery = &Error{Code: 2006, Msg: "user unauthorized to make this query"}
+	ErrInvalidCursor     = &Error{Code: 2007, Msg: "invalid or tampered pagination cursor"}
+	ErrPostNotFound      = &Error{Code: 2008, Msg: "post not found"}
+	ErrPostTooLong       = &Error{Code: 2009, Msg: "post is too long"}
+	ErrTooManyEntities   = &Error{Code: 2010, Msg: "too many mentions or hashtags"}
 
 	// Everything else
 	ErrUnknown        = &Error{Code: 5000, Msg: "unknown provider error"}
@@ -35,13 +43,50 @@ var (
 	ErrUnsupported    = &Error{Code: 5002, Msg: "unsupported operation"}
 	ErrNotImplemented = &Error{Code: 5003, Msg: "not implemented"}
 	ErrInvalidContent = &Error{Code: 5004, Msg: "empty title and url provided"}
+	ErrCircuitOpen    = &Error{Code: 5005, Msg: "provider is unavailable, try again later"}
+	ErrNoCursorKey    = &Error{Code: 5006, Msg: "cursor key is not configured"}
+)
+
+// ErrorCategory classifies provider errors by how callers should handle them
+type ErrorCategory int
+
+const (
+	CategoryUnknown     ErrorCategory = iota
+	CategoryAuth                      // the user has to (re)connect their account
+	CategoryQuota                     // rate limited, try again later
+	CategoryClient                    // invalid request, don't try again as is
+	CategoryProvider                  // provider failure, may succeed later
+	CategoryUnsupported               // not supported by the provider
 )
 
+var errorCategoryNames = map[ErrorCategory]string{
+	CategoryUnknown:     "unknown",
+	CategoryAuth:        "auth",
+	CategoryQuota:       "quota",
+	CategoryClient:      "client",
+	CategoryProvider:    "provider",
+	CategoryUnsupported: "unsupported",
+}
+
+func (c ErrorCategory) String() string {
+	return errorCategoryNames[c]
+}
+
 // Provider-specific error
 type Error struct {
 	err  error  // the original error
 	Code int    // provider error code
 	Msg  string // provider error string
+
+	// ProviderCode and HTTPStatus are the raw error code and the response
+	// status of the provider, when known
+	ProviderCode int
+	HTTPStatus   int
+
+	// RetryAfter is how long to wait before trying a rate limited call
+	// again, and ResetAt when, if the provider tells
+	RetryAfter time.Duration
+	ResetAt    time.Time
 }
 
 func (e *Error) Error() string {
@@ -53,5 +98,140 @@ func (e *Error) Error() string {
 }
 
 func (e *Error) Err(err error) error {
-	return &Error{err: err, Code: e.Code, Msg: e.Msg}
+	c := *e
+	c.err = err
+	return &c
+}
+
+// ProviderErr is like Err, keeping the raw error code and response status
+// of the provider
+func (e *Error) ProviderErr(err error, providerCode int, httpStatus int) *Error {
+	c := *e
+	c.err = err
+	c.ProviderCode = providerCode
+	c.HTTPStatus = httpStatus
+	return &c
+}
+
+// Unwrap returns the original error
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// errorParents are the codes which refined a more general error, and still
+// match it so the callers checking the general one keep working
+var errorParents = map[int]int{
+	ErrDuplicatePost.Code: ErrWritingPost.Code,
+}
+
+// Is reports whether target is an Error of the same code, or of the code
+// it refines, so wrapped errors match their sentinel, ie.
+// errors.Is(err, ErrHitRateLimit), and errors.Is(err, ErrWritingPost) for
+// ErrDuplicatePost
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	parent, refines := errorParents[e.Code]
+	return t.Code == e.Code || (refines && t.Code == parent)
+}
+
+// Category returns the category of the error code
+func (e *Error) Category() ErrorCategory {
+	switch e.Code {
+	case ErrHitRateLimit.Code:
+		return CategoryQuota
+	case ErrNoCredentials.Code, ErrAuthFailed.Code, ErrInvalidToken.Code, ErrExpiredToken.Code,
+		ErrBadAccount.Code, ErrMustReauth.Code, ErrEmptyCode.Code, ErrNoQueryAccess.Code,
+		ErrUnauthorizedQuery.Code:
+		return CategoryAuth
+	case ErrUnknownProviderID.Code, ErrInvalidQuery.Code, ErrInvalidAsset.Code, ErrDuplicatePost.Code,
+		ErrInvalidCursor.Code, ErrInvalidContent.Code, ErrPostNotFound.Code, ErrPostTooLong.Code,
+		ErrTooManyEntities.Code:
+		return CategoryClient
+	case ErrProviderDown.Code, ErrCircuitOpen.Code, ErrGetUser.Code, ErrWritingPost.Code:
+		return CategoryProvider
+	case ErrUnsupported.Code, ErrNotImplemented.Code, ErrUsernameSearch.Code:
+		return CategoryUnsupported
+	}
+	return CategoryUnknown
+}
+
+// IsRetryable reports whether the call may succeed when tried again as is:
+// the provider is down, the rate limit resets at a known time, or the
+// original error is a network error
+func (e *Error) IsRetryable() bool {
+	switch e.Code {
+	case ErrProviderDown.Code:
+		return true
+	case ErrHitRateLimit.Code:
+		return RetryAfter(e, -1) >= 0
+	}
+	return isNetError(e.err)
+}
+
+// NeedsReauth reports whether the user has to re-connect their account
+// for the call to succeed
+func (e *Error) NeedsReauth() bool {
+	switch e.Code {
+	case ErrNoCredentials.Code, ErrAuthFailed.Code, ErrInvalidToken.Code, ErrExpiredToken.Code, ErrMustReauth.Code:
+		return true
+	}
+	return false
+}
+
+// ErrorCategoryOf returns the category of a provider error, wrapped or not
+func ErrorCategoryOf(err error) ErrorCategory {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Category()
+	}
+	return CategoryUnknown
+}
+
+// NeedsReauth reports whether err is a provider error which requires the
+// user to re-connect their account
+func NeedsReauth(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.NeedsReauth()
+}
+
+// RetryAt returns the error with the time the call may be tried again, or
+// the error itself for a zero time
+func (e *Error) RetryAt(resetAt time.Time) *Error {
+	if resetAt.IsZero() {
+		return e
+	}
+	c := *e
+	c.ResetAt = resetAt
+	c.RetryAfter = time.Until(resetAt)
+	if c.RetryAfter < 0 {
+		c.RetryAfter = 0
+	}
+	return &c
+}
+
+// IsRateLimit reports whether err is a ErrHitRateLimit error
+func IsRateLimit(err error) bool {
+	return errors.Is(err, ErrHitRateLimit)
+}
+
+// RetryAfter returns how long to wait before trying again the call which
+// failed with err, or def when the provider doesn't tell
+func RetryAfter(err error, def time.Duration) time.Duration {
+	var e *Error
+	if !errors.As(err, &e) {
+		return def
+	}
+	if !e.ResetAt.IsZero() {
+		if d := time.Until(e.ResetAt); d > 0 {
+			return d
+		}
+		return 0
+	}
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	return def
 }
@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := ErrDuplicatePost.ProviderErr(errors.New("status is a duplicate"), 187, 403)
+	if !errors.Is(err, ErrDuplicatePost) || !errors.Is(err, ErrWritingPost) {
+		t.Errorf("expecting a duplicate post to match ErrDuplicatePost and ErrWritingPost")
+	}
+	if errors.Is(ErrWritingPost.Err(nil), ErrDuplicatePost) {
+		t.Errorf("expecting ErrWritingPost not to match ErrDuplicatePost")
+	}
+	if errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expecting a duplicate post not to match other errors")
+	}
+	if wrapped := fmt.Errorf("publishing: %w", err); !errors.Is(wrapped, ErrWritingPost) {
+		t.Errorf("expecting a wrapped duplicate post to match ErrWritingPost")
+	}
+	if err.HTTPStatus != 403 || err.ProviderCode != 187 {
+		t.Errorf("expecting the provider code and status to be kept, got %d, %d", err.ProviderCode, err.HTTPStatus)
+	}
+}
+
+func TestErrorCategory(t *testing.T) {
+	tests := map[error]ErrorCategory{
+		ErrHitRateLimit.RetryAt(time.Now()):        CategoryQuota,
+		ErrExpiredToken:                            CategoryAuth,
+		ErrNoQueryAccess.Err(errors.New("page")):   CategoryAuth,
+		ErrDuplicatePost:                           CategoryClient,
+		ErrPostTooLong:                             CategoryClient,
+		ErrWritingPost:                             CategoryProvider,
+		ErrCircuitOpen:                             CategoryProvider,
+		ErrUsernameSearch:                          CategoryUnsupported,
+		ErrUnknown:                                 CategoryUnknown,
+		fmt.Errorf("wrapped: %w", ErrProviderDown): CategoryProvider,
+		errors.New("not a provider error"):         CategoryUnknown,
+	}
+	for err, want := range tests {
+		if got := ErrorCategoryOf(err); got != want {
+			t.Errorf("%v: expecting category %v, got %v", err, want, got)
+		}
+	}
+}
+
+func TestErrorIsRetryable(t *testing.T) {
+	netErr := &net.OpError{Op: "read", Err: errors.New("connection reset")}
+	tests := map[error]bool{
+		ErrProviderDown: true,
+		ErrHitRateLimit.RetryAt(time.Now().Add(time.Hour)): true,
+		ErrHitRateLimit:                      false, // unknown reset
+		ErrUnknown.Err(netErr):               true,
+		ErrUnknown.Err(context.Canceled):     false,
+		ErrUnknown.Err(errors.New("failed")): false,
+		ErrPostNotFound:                      false,
+		ErrDuplicatePost:                     false,
+		ErrExpiredToken:                      false,
+		netErr:                               true,
+		context.DeadlineExceeded:             false,
+	}
+	for err, want := range tests {
+		if got := Retryable(err); got != want {
+			t.Errorf("%v: expecting retryable %v, got %v", err, want, got)
+		}
+	}
+}
+
+func TestNeedsReauth(t *testing.T) {
+	for _, err := range []error{ErrNoCredentials, ErrAuthFailed, ErrInvalidToken, ErrExpiredToken, ErrMustReauth.Err(errors.New("revoked"))} {
+		if !NeedsReauth(err) {
+			t.Errorf("%v: expecting the user to re-connect", err)
+		}
+	}
+	// Auth errors the user can't fix by re-connecting
+	for _, err := range []error{ErrBadAccount, ErrNoQueryAccess, ErrUnauthorizedQuery, ErrHitRateLimit, errors.New("failed"), nil} {
+		if NeedsReauth(err) {
+			t.Errorf("%v: expecting no re-connect", err)
+		}
+	}
+}
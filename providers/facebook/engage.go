@@ -0,0 +1,46 @@
+package facebook
+
+import (
+	"context"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+// Like a post, or a comment
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/object/likes#publish
+func (p *Provider) Like(ctx context.Context, postID string) error {
+	resp, err := p.api.Post("/"+postID+"/likes", nil)
+	_, err = getActualResponseAndError(resp, err)
+	if err != nil {
+		return p.apiError(err)
+	}
+	return nil
+}
+
+// Unlike a post, or a comment
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/object/likes#delete
+func (p *Provider) Unlike(ctx context.Context, postID string) error {
+	resp, err := p.api.Delete("/"+postID+"/likes", nil)
+	_, err = getActualResponseAndError(resp, err)
+	if err != nil {
+		return p.apiError(err)
+	}
+	return nil
+}
+
+// Share reshares a post to the user's feed, as a link to the post, facebook
+// has no share api
+func (p *Provider) Share(ctx context.Context, postID string) (*social.Post, error) {
+	return p.Publish(ctx, &providers.PostRequest{Link: postURL(postID)})
+}
+
+// Follow returns ErrUnsupported, facebook doesn't allow following users
+func (p *Provider) Follow(ctx context.Context, userID string) error {
+	return providers.ErrUnsupported
+}
+
+// Unfollow returns ErrUnsupported, facebook doesn't allow following users
+func (p *Provider) Unfollow(ctx context.Context, userID string) error {
+	return providers.ErrUnsupported
+}
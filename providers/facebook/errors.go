@@ -2,8 +2,11 @@ package facebook
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/go-social/social/providers"
 	fb "github.com/huandu/facebook"
@@ -11,57 +14,113 @@ import (
 
 var errNodeTypePage = errors.New("Tried accessing a nonexisting field on a node type Page")
 
-// Facebook error codes
+// Facebook error codes, with the http status of the response
 // https://developers.facebook.com/docs/graph-api/using-graph-api/v2.2#errors
-func providerError(err error) error {
+func providerError(err error, status int) error {
 	if err == nil {
 		return nil
 	}
 
+	// Calls held back by the rate limit transport
+	var perr *providers.Error
+	if errors.As(err, &perr) {
+		return perr
+	}
+
 	// Most probably oauth2 error.
 	if e, ok := err.(*url.Error); ok {
 		if strings.Contains(strings.ToLower(e.Error()), "unauthorized") {
-			return providers.ErrAuthFailed
+			return providers.ErrAuthFailed.Err(err)
 		}
 		return providers.ErrUnknown.Err(err)
 	}
 
-	if e, ok := err.(*fb.Error); ok {
-		switch e.Code {
-		case 1, 2:
-			return providers.ErrProviderDown
-
-		case 4, 17, 341:
-			return providers.ErrHitRateLimit
-
-		case 10:
-			return providers.ErrMustReauth
-
-		case 100:
-			// This happens when the user tries to get an e-mail from a page.
-			// "(#100) Tried accessing nonexisting field (email) on node type (Page)"
-			if strings.Contains(e.Error(), "Page") {
-				return errNodeTypePage
-			}
-		case 102, 190:
-			switch e.ErrorSubcode {
-			case 458, 459, 460:
-				return providers.ErrMustReauth
-			case 463:
-				return providers.ErrExpiredToken
-			case 464:
-				return providers.ErrBadAccount
-			default:
-				return providers.ErrInvalidToken
-			}
-
-		case 506:
-			return providers.ErrDuplicatePost
-
-		case 803:
-			return providers.ErrUsernameSearch
+	e, ok := err.(*fb.Error)
+	if !ok {
+		return providers.ErrUnknown.Err(err)
+	}
+
+	perr = providers.ErrUnknown
+	switch e.Code {
+	case 1, 2:
+		perr = providers.ErrProviderDown
+
+	case 4, 17, 32, 341, 613, 80001, 80002, 80004, 80005, 80006, 80008, 80014:
+		perr = providers.ErrHitRateLimit
+
+	case 10:
+		perr = providers.ErrMustReauth
+
+	case 100:
+		// "Unsupported get/post/delete request. Object with ID does not exist"
+		if e.ErrorSubcode == 33 {
+			perr = providers.ErrPostNotFound
+			break
+		}
+		// This happens when the user tries to get an e-mail from a page.
+		// "(#100) Tried accessing nonexisting field (email) on node type (Page)"
+		if strings.Contains(e.Error(), "Page") {
+			return providers.ErrNoQueryAccess.ProviderErr(fmt.Errorf("%w: %v", errNodeTypePage, e), e.Code, status)
 		}
+
+	case 102, 190:
+		switch e.ErrorSubcode {
+		case 458, 459, 460:
+			perr = providers.ErrMustReauth
+		case 463:
+			perr = providers.ErrExpiredToken
+		case 464:
+			perr = providers.ErrBadAccount
+		default:
+			perr = providers.ErrInvalidToken
+		}
+
+	case 506:
+		perr = providers.ErrDuplicatePost
+
+	case 803:
+		perr = providers.ErrUsernameSearch
+	}
+
+	return perr.ProviderErr(e, e.Code, status)
+}
+
+// apiError is providerError, with the status of the last response, and the
+// reset time of the tracked usage for rate limit errors, as facebook
+// doesn't tell in the error
+func (p *Provider) apiError(err error) error {
+	return p.rateLimits.RateLimitError("", providerError(err, p.status.Last()))
+}
+
+// statusTransport records the status of the last response, which the graph
+// api errors don't include. The calls of a session are made one at a time,
+// so it's the status of the call which failed.
+type statusTransport struct {
+	Base http.RoundTripper
+
+	mu     sync.Mutex
+	status int
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Base.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
 	}
+	t.mu.Lock()
+	t.status = status
+	t.mu.Unlock()
+	return resp, err
+}
 
-	return providers.ErrUnknown.Err(err)
+// Last returns the status of the last response, or 0 when the last call
+// failed without a response
+func (t *statusTransport) Last() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
 }
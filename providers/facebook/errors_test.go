@@ -0,0 +1,76 @@
+package facebook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-social/social/providers"
+	fb "github.com/huandu/facebook"
+)
+
+func TestProviderError(t *testing.T) {
+	tests := []struct {
+		err    *fb.Error
+		status int
+		want   *providers.Error
+	}{
+		{&fb.Error{Code: 2}, 500, providers.ErrProviderDown},
+		{&fb.Error{Code: 4}, 403, providers.ErrHitRateLimit},
+		{&fb.Error{Code: 100, ErrorSubcode: 33}, 400, providers.ErrPostNotFound},
+		{&fb.Error{Code: 100, Message: "(#100) Tried accessing nonexisting field (email) on node type (Page)"}, 400, providers.ErrNoQueryAccess},
+		{&fb.Error{Code: 190, ErrorSubcode: 463}, 401, providers.ErrExpiredToken},
+		{&fb.Error{Code: 190}, 401, providers.ErrInvalidToken},
+		{&fb.Error{Code: 506}, 400, providers.ErrDuplicatePost},
+		{&fb.Error{Code: 1234}, 400, providers.ErrUnknown},
+	}
+	for _, tt := range tests {
+		err := providerError(tt.err, tt.status)
+		var perr *providers.Error
+		if !errors.As(err, &perr) || perr.Code != tt.want.Code {
+			t.Errorf("code %d/%d: expecting %v, got %v", tt.err.Code, tt.err.ErrorSubcode, tt.want, err)
+			continue
+		}
+		if perr.ProviderCode != tt.err.Code || perr.HTTPStatus != tt.status {
+			t.Errorf("code %d: expecting the provider code and status %d, got %d, %d", tt.err.Code, tt.status, perr.ProviderCode, perr.HTTPStatus)
+		}
+	}
+
+	if err := providerError(&fb.Error{Code: 506}, 400); !errors.Is(err, providers.ErrWritingPost) {
+		t.Errorf("expecting a duplicate post to match ErrWritingPost, got %v", err)
+	}
+}
+
+func TestStatusTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 200, "message": "Permissions error"}}`))
+	}))
+	defer srv.Close()
+
+	status := &statusTransport{Base: http.DefaultTransport}
+	if status.Last() != 0 {
+		t.Errorf("expecting no status before any call")
+	}
+	resp, err := (&http.Client{Transport: status}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if status.Last() != http.StatusForbidden {
+		t.Errorf("expecting the status of the response, got %d", status.Last())
+	}
+
+	p := &Provider{rateLimits: &providers.RateLimitTransport{}, status: status}
+	var perr *providers.Error
+	if err := p.apiError(&fb.Error{Code: 200}); !errors.As(err, &perr) || perr.HTTPStatus != http.StatusForbidden {
+		t.Errorf("expecting the api error with the response status, got %v", err)
+	}
+
+	// The status is reset by failed calls
+	srv.Close()
+	if _, err := (&http.Client{Transport: status}).Get(srv.URL); err == nil || status.Last() != 0 {
+		t.Errorf("expecting no status without a response, got %d", status.Last())
+	}
+}
@@ -1,8 +1,12 @@
 package facebook
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -75,8 +79,10 @@ var (
 )
 
 type Provider struct {
-	creds social.Credentials
-	api   *fb.Session
+	creds      social.Credentials
+	api        *fb.Session
+	rateLimits *providers.RateLimitTransport
+	status     *statusTransport
 }
 
 func New(ctx context.Context, creds social.Credentials) (providers.ProviderSession, error) {
@@ -95,36 +101,247 @@ func New(ctx context.Context, creds social.Credentials) (providers.ProviderSessi
 		token.Expiry = *expiresAt
 	}
 
+	// Facebook rate limits are per app, page or business, not per endpoint
+	rateLimits := &providers.RateLimitTransport{
+		Credential: creds.AccessToken(),
+		Endpoint:   func(req *http.Request) string { return "" },
+		Parse:      parseRateLimit,
+	}
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		rateLimits.Base = c.Transport
+	}
+	status := &statusTransport{Base: rateLimits}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: status})
+
 	api := &fb.Session{
 		Version:    FacebookApiVersion,
 		HttpClient: conf.Client(ctx, token),
 	}
+	p := &Provider{api: api, creds: creds, rateLimits: rateLimits, status: status}
 
 	if err := api.Validate(); err != nil {
-		return nil, providerError(err)
+		return nil, p.apiError(err)
 	}
 
-	return &Provider{api: api, creds: creds}, nil
+	return p, nil
 }
 
 func (p *Provider) ID() string {
 	return ProviderID
 }
 
-func (p *Provider) Post(ctx context.Context, msg string, shareLink string) (*social.Post, error) {
-	return nil, providers.ErrNotImplemented
+func (p *Provider) Credentials() social.Credentials {
+	return p.creds
+}
+
+// Publish posts to the user's feed, with photos or a video, or comments on
+// a post when replying to it. Quoted posts are shared as links.
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/user/feed#publish
+func (p *Provider) Publish(ctx context.Context, req *providers.PostRequest) (*social.Post, error) {
+	if err := providers.PrepareMedia(req.Media, maxImages); err != nil {
+		return nil, err
+	}
+	if req.ReplyTo != "" {
+		return p.comment(ctx, req)
+	}
+
+	link := req.Link
+	if req.QuoteURL != "" {
+		if link != "" {
+			return nil, providers.ErrUnsupportedField(ProviderID, "quote with a link")
+		}
+		link = req.QuoteURL
+	}
+	if req.LinkPreview != nil && (link == "" || len(req.Media) > 0) {
+		return nil, providers.ErrUnsupportedField(ProviderID, "link preview without a link, or with media")
+	}
+
+	params, err := publishParams(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Media) == 1 && req.Media[0].Type != social.MediaImage {
+		return p.postVideo(ctx, req.Media[0], appendLink(req.Message, link), params)
+	}
+
+	params["message"] = req.Message
+	if len(req.Media) > 0 {
+		// The link preview would replace the photos
+		params["message"] = appendLink(req.Message, link)
+	} else if link != "" {
+		params["link"] = link
+	}
+	if lp := req.LinkPreview; lp != nil {
+		setParam(params, "name", lp.Title)
+		setParam(params, "description", lp.Description)
+		setParam(params, "picture", lp.ImageURL)
+	}
+
+	for i, m := range req.Media {
+		id, err := p.uploadPhoto(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		params[fmt.Sprintf("attached_media[%d]", i)] = fmt.Sprintf(`{"media_fbid":%q}`, id)
+	}
+
+	resp, err := p.api.Post("/me/feed", params)
+	fbResponse, err := getActualResponseAndError(resp, err)
+	if err != nil {
+		return nil, p.apiError(err)
+	}
+
+	return &social.Post{
+		Provider: ProviderID,
+		ID:       fbResponse.ID,
+		URL:      postURL(fbResponse.ID),
+	}, nil
+}
+
+// DeletePost deletes a post, or a comment, of the user
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/post#deleting
+func (p *Provider) DeletePost(ctx context.Context, id string) error {
+	resp, err := p.api.Delete("/"+id, nil)
+	_, err = getActualResponseAndError(resp, err)
+	if err != nil {
+		return p.apiError(err)
+	}
+	return nil
+}
+
+// EditPost edits the message of a post, or a comment, of the user. The
+// other fields of the request can't be edited.
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/post#updating
+func (p *Provider) EditPost(ctx context.Context, id string, req *providers.PostRequest) (*social.Post, error) {
+	if req.Link != "" || req.LinkPreview != nil || len(req.Media) > 0 || req.ReplyTo != "" ||
+		req.QuoteURL != "" || req.Visibility != "" || req.ScheduledAt != nil {
+		return nil, providers.ErrUnsupportedField(ProviderID, "edit of fields other than the message")
+	}
+
+	params := getFbParams(req.Options)
+	params["message"] = req.Message
+
+	resp, err := p.api.Post("/"+id, params)
+	_, err = getActualResponseAndError(resp, err)
+	if err != nil {
+		return nil, p.apiError(err)
+	}
+
+	return &social.Post{
+		Provider: ProviderID,
+		ID:       id,
+		URL:      postURL(id),
+		Contents: req.Message,
+	}, nil
+}
+
+// comment publishes a reply as a comment of the post, with a photo at most
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/object/comments#publish
+func (p *Provider) comment(ctx context.Context, req *providers.PostRequest) (*social.Post, error) {
+	switch {
+	case req.QuoteURL != "":
+		return nil, providers.ErrUnsupportedField(ProviderID, "quote in a comment")
+	case req.LinkPreview != nil:
+		return nil, providers.ErrUnsupportedField(ProviderID, "link preview in a comment")
+	case req.Visibility != "":
+		return nil, providers.ErrUnsupportedField(ProviderID, "visibility of a comment")
+	case req.ScheduledAt != nil:
+		return nil, providers.ErrUnsupportedField(ProviderID, "scheduled time of a comment")
+	case len(req.Media) > 1 || (len(req.Media) == 1 && req.Media[0].Type != social.MediaImage):
+		return nil, providers.ErrInvalidAsset.Err(errors.New("facebook comments can only have a photo"))
+	}
+
+	params := getFbParams(req.Options)
+	params["message"] = appendLink(req.Message, req.Link)
+	if len(req.Media) > 0 {
+		params["source"] = fb.Data(req.Media[0].Filename, bytes.NewReader(req.Media[0].Data))
+	}
+
+	resp, err := p.api.Post("/"+req.ReplyTo+"/comments", params)
+	fbResponse, err := getActualResponseAndError(resp, err)
+	if err != nil {
+		return nil, p.apiError(err)
+	}
+
+	return &social.Post{
+		Provider: ProviderID,
+		ID:       fbResponse.ID,
+		URL:      commentURL(req.ReplyTo, fbResponse.ID),
+	}, nil
+}
+
+// publishParams returns the options, privacy and schedule params of a post
+func publishParams(req *providers.PostRequest) (fb.Params, error) {
+	params := getFbParams(req.Options)
+
+	if req.Visibility != "" {
+		privacy, ok := privacyValues[req.Visibility]
+		if !ok {
+			return nil, providers.ErrUnsupportedField(ProviderID, "visibility "+string(req.Visibility))
+		}
+		params["privacy"] = fmt.Sprintf(`{"value":%q}`, privacy)
+	}
+
+	// Only pages may schedule posts, facebook rejects the others
+	if req.ScheduledAt != nil {
+		params["published"] = false
+		params["scheduled_publish_time"] = strconv.FormatInt(req.ScheduledAt.Unix(), 10)
+	}
+	return params, nil
+}
+
+// privacyValues are the facebook privacy values of the post visibilities
+var privacyValues = map[providers.Visibility]string{
+	providers.VisibilityPublic:  "EVERYONE",
+	providers.VisibilityFriends: "ALL_FRIENDS",
+	providers.VisibilityPrivate: "SELF",
+}
+
+func setParam(params fb.Params, key string, value string) {
+	if value != "" {
+		params[key] = value
+	}
 }
 
 func (p *Provider) Search(query providers.Query) (social.Posts, *providers.Cursor, error) {
 	return nil, nil, providers.ErrUnsupported
 }
 
+// Get a user's feed, including posts by others on their wall
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/user/feed
 func (p *Provider) GetFeed(query providers.Query) (social.Posts, *providers.Cursor, error) {
-	return nil, nil, providers.ErrNotImplemented
+	return p.getPosts("feed", query)
 }
 
+// Get a user's own posts
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/user/feed
 func (p *Provider) GetPosts(query providers.Query) (social.Posts, *providers.Cursor, error) {
-	return nil, nil, providers.ErrNotImplemented
+	return p.getPosts("posts", query)
+}
+
+func (p *Provider) getPosts(edge string, query providers.Query) (social.Posts, *providers.Cursor, error) {
+	node := "me"
+	if query.UserID != "" {
+		node = query.UserID
+	} else if query.Username != "" {
+		node = query.Username
+	}
+
+	args, err := postsArgs(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := p.api.Get("/"+node+"/"+edge, getFbParams(args))
+	fbResponse, err := getActualResponseAndError(resp, err)
+	if err != nil {
+		return nil, nil, p.apiError(err)
+	}
+
+	posts := (&Mapper{}).BuildPosts(fbResponse.Data)
+	cursor := providers.NewCursor(query, pagingArgs(fbResponse.Paging.Previous), pagingArgs(fbResponse.Paging.Next))
+
+	return posts, cursor, nil
 }
 
 func (p *Provider) GetUser(query providers.Query) (*social.User, error) {
@@ -139,19 +356,19 @@ func (p *Provider) GetUser(query providers.Query) (*social.User, error) {
 	args := url.Values{}
 	args.Set("fields", userFields)
 	resp, err = p.api.Get(username, getFbParams(args))
-	if err != nil && providerError(err) == errNodeTypePage {
+	if err != nil && errors.Is(providerError(err, 0), errNodeTypePage) {
 		// Try again, this time this will query for basic information only.
 		args := url.Values{}
 		args.Set("fields", basicFields)
 		resp, err = p.api.Get(username, getFbParams(args))
 	}
 	if err != nil {
-		return nil, providerError(err)
+		return nil, p.apiError(err)
 	}
 
 	fbResponse, err := getActualResponseAndError(resp, err)
 	if err != nil {
-		return nil, providerError(err)
+		return nil, p.apiError(err)
 	}
 
 	user := &social.User{
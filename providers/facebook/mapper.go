@@ -22,19 +22,31 @@ func (m *Mapper) BuildPosts(fbPosts []FbPost) social.Posts {
 }
 
 func (m *Mapper) BuildPost(fbPost FbPost) *social.Post {
-	var post *social.Post
+	post := &social.Post{Raw: fbPost}
 
-	idSlice := strings.Split(fbPost.ID, "_")
-	if len(idSlice) < 2 {
+	if !strings.Contains(fbPost.ID, "_") {
 		return nil
 	}
-	userID, postID := idSlice[0], idSlice[1]
 
-	post.URL = "https://facebook.com/" + userID + "/posts/" + postID // TODO: need this...? cuz there is fbPost.Link ..?
+	post.URL = postURL(fbPost.ID) // TODO: need this...? cuz there is fbPost.Link ..?
 	post.ID = fbPost.ID
 	post.Provider = ProviderID
 	post.NumShares = int32(fbPost.Shares.Count)
 
+	post.Contents = fbPost.Message
+	if post.Contents == "" {
+		post.Contents = fbPost.Story
+	}
+	if fbPost.Link != "" {
+		post.Links = append(post.Links, fbPost.Link)
+	}
+
+	providers.AddEntities(post, nil)
+
+	for _, a := range fbPost.Attachments.Data {
+		post.Media = append(post.Media, m.BuildMedia(a)...)
+	}
+
 	post.Author = social.User{
 		ID:         fbPost.From.ID,
 		Name:       fbPost.From.Name,
@@ -50,3 +62,101 @@ func (m *Mapper) BuildPost(fbPost FbPost) *social.Post {
 
 	return post
 }
+
+// BuildComments maps the comments of a post as its replies
+func (m *Mapper) BuildComments(comments []FbComment, postID string) social.Posts {
+	var posts social.Posts
+	for _, c := range comments {
+		posts.Add(m.BuildComment(c, postID))
+	}
+	return posts
+}
+
+func (m *Mapper) BuildComment(c FbComment, postID string) *social.Post {
+	post := &social.Post{
+		Raw:      c,
+		ID:       c.ID,
+		Provider: ProviderID,
+		URL:      c.PermalinkURL,
+		Contents: c.Message,
+		NumLikes: int32(c.LikeCount),
+		Media:    m.BuildMedia(c.Attachment),
+		ParentID: postID,
+		Author: social.User{
+			ID:         c.From.ID,
+			Name:       c.From.Name,
+			ProfileURL: "https://facebook.com/profile.php?id=" + c.From.ID,
+			AvatarURL:  fmt.Sprintf("https://graph.facebook.com/%v/picture?type=large", c.From.ID),
+		},
+	}
+	if c.Parent.ID != "" {
+		post.ParentID = c.Parent.ID
+	}
+	if post.URL == "" {
+		post.URL = commentURL(postID, c.ID)
+	}
+	providers.AddEntities(post, nil)
+
+	publishedAt, _ := providers.GetUTCTimeForLayout(c.CreatedTime, timeLayout)
+	post.PublishedAt = &publishedAt
+
+	return post
+}
+
+// postURL returns the url of a post from its "{user-id}_{post-id}" id, or
+// of the object for other ids, ie. videos
+func postURL(id string) string {
+	idSlice := strings.Split(id, "_")
+	if len(idSlice) < 2 {
+		return "https://facebook.com/" + id
+	}
+	return "https://facebook.com/" + idSlice[0] + "/posts/" + idSlice[1]
+}
+
+// commentURL returns the url of a comment of a post, from its
+// "{post-id}_{comment-id}" id
+func commentURL(postID string, commentID string) string {
+	if i := strings.LastIndex(commentID, "_"); i >= 0 {
+		commentID = commentID[i+1:]
+	}
+	return postURL(postID) + "?comment_id=" + commentID
+}
+
+// BuildMedia maps the images, videos and gifs of an attachment, and of its
+// subattachments for albums
+func (m *Mapper) BuildMedia(a FbAttachment) []*social.Media {
+	var media []*social.Media
+	if t := attachmentMediaType(a.Type); t != "" && a.Media.Image.Src != "" {
+		md := &social.Media{
+			Type:   t,
+			URL:    a.Media.Image.Src,
+			Width:  a.Media.Image.Width,
+			Height: a.Media.Image.Height,
+		}
+		if t != social.MediaImage {
+			md.ThumbnailURL = a.Media.Image.Src
+			if a.Media.Source != "" {
+				md.URL = a.Media.Source
+			}
+		}
+		media = append(media, md)
+	}
+	for _, sub := range a.SubAttachments.Data {
+		media = append(media, m.BuildMedia(sub)...)
+	}
+	return media
+}
+
+// attachmentMediaType returns the media type of a facebook attachment type,
+// or "" for the attachments which aren't media, ie. shared links
+func attachmentMediaType(attachmentType string) social.MediaType {
+	switch {
+	case attachmentType == "photo" || attachmentType == "cover_photo" || attachmentType == "profile_media":
+		return social.MediaImage
+	case strings.HasPrefix(attachmentType, "animated_image"):
+		return social.MediaGIF
+	case strings.HasPrefix(attachmentType, "video"):
+		return social.MediaVideo
+	}
+	return ""
+}
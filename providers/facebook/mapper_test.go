@@ -0,0 +1,40 @@
+package facebook
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildPost(t *testing.T) {
+	var fbPosts []FbPost
+	err := json.Unmarshal([]byte(`[
+		{"id": "1_2", "from": {"id": "1", "name": "Jane"}, "message": "Hello #go", "link": "https://go.dev",
+		 "created_time": "2020-01-02T03:04:05+0000"},
+		{"id": "1_3", "from": {"id": "1", "name": "Jane"}, "story": "Jane updated her profile picture."},
+		{"id": "4"}
+	]`), &fbPosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := (&Mapper{}).BuildPosts(fbPosts)
+	if len(posts) != 2 {
+		t.Fatalf("expecting the posts without a user id to be skipped, got %d posts", len(posts))
+	}
+
+	p := posts[0]
+	if p.ID != "1_2" || p.Contents != "Hello #go" || p.Author.Name != "Jane" || p.Provider != ProviderID {
+		t.Errorf("unexpected post %+v", p)
+	}
+	if len(p.Links) != 1 || p.Links[0] != "https://go.dev" || len(p.Tags) != 1 || p.Tags[0] != "go" {
+		t.Errorf("unexpected links %v and tags %v", p.Links, p.Tags)
+	}
+	if p.PublishedAt == nil || p.PublishedAt.Year() != 2020 {
+		t.Errorf("unexpected publish date %v", p.PublishedAt)
+	}
+
+	// Posts without a message have the story as contents
+	if posts[1].Contents != "Jane updated her profile picture." {
+		t.Errorf("unexpected contents %q", posts[1].Contents)
+	}
+}
@@ -0,0 +1,70 @@
+package facebook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+	fb "github.com/huandu/facebook"
+)
+
+// maxImages is the number of photos posted at once
+const maxImages = 10
+
+// uploadPhoto uploads an unpublished photo, to be attached to a feed post,
+// and returns its id
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/user/photos#publish
+func (p *Provider) uploadPhoto(ctx context.Context, m *providers.MediaUpload) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	params := fb.Params{
+		"source":    fb.Data(m.Filename, bytes.NewReader(m.Data)),
+		"published": false,
+	}
+	if m.AltText != "" {
+		params["alt_text_custom"] = m.AltText
+	}
+
+	resp, err := p.api.Post("/me/photos", params)
+	fbResponse, err := getActualResponseAndError(resp, err)
+	if err != nil {
+		return "", p.apiError(err)
+	}
+	return fbResponse.ID, nil
+}
+
+// postVideo publishes a video, or gif, with the description and the
+// publish params of the post
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/user/videos#publish
+func (p *Provider) postVideo(ctx context.Context, m *providers.MediaUpload, description string, params fb.Params) (*social.Post, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	params["source"] = fb.Data(m.Filename, bytes.NewReader(m.Data))
+	params["description"] = description
+
+	resp, err := p.api.Post("/me/videos", params)
+	fbResponse, err := getActualResponseAndError(resp, err)
+	if err != nil {
+		return nil, p.apiError(err)
+	}
+
+	return &social.Post{
+		Provider: ProviderID,
+		ID:       fbResponse.ID,
+		URL:      postURL(fbResponse.ID),
+	}, nil
+}
+
+// appendLink appends the link to the message, for the posts which can't
+// have a link preview
+func appendLink(msg string, link string) string {
+	if link == "" || strings.Contains(msg, link) {
+		return msg
+	}
+	return fmt.Sprintf("%s %s", strings.TrimSpace(msg), link)
+}
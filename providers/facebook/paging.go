@@ -0,0 +1,79 @@
+package facebook
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/go-social/social/providers"
+)
+
+// Facebook pages with the args of the paging urls of its responses, ie.
+// "until=1364587774&__paging_token=...", rather than with post ids. The
+// paging args are kept url encoded as the cursor ids: UntilID for the
+// next page, and SinceID for the previous one.
+// Network docs: https://developers.facebook.com/docs/graph-api/using-graph-api/#paging
+
+// pagingKeys are the args of the facebook paging urls
+var pagingKeys = []string{"since", "until", "after", "before", "__paging_token", "__previous"}
+
+// postsArgs returns the args of a feed or posts request for the query
+func postsArgs(query providers.Query) (url.Values, error) {
+	args := url.Values{}
+	args.Set("fields", postFields)
+	args.Set("limit", strconv.Itoa(query.Limit))
+	if !query.Since.IsZero() {
+		args.Set("since", strconv.FormatInt(query.Since.Unix(), 10))
+	}
+	if !query.Until.IsZero() {
+		args.Set("until", strconv.FormatInt(query.Until.Unix(), 10))
+	}
+
+	if err := setPagingArgs(args, query); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// setPagingArgs sets the paging args of the query cursor ids, over the
+// query bounds. Other args are ignored, so the cursor ids can't change
+// the request otherwise.
+func setPagingArgs(args url.Values, query providers.Query) error {
+	pagingID := query.UntilID
+	if pagingID == "" {
+		pagingID = query.SinceID
+	}
+	if pagingID == "" {
+		return nil
+	}
+	paging, err := url.ParseQuery(pagingID)
+	if err != nil {
+		return providers.ErrInvalidQuery.Err(err)
+	}
+	for _, k := range pagingKeys {
+		if v := paging.Get(k); v != "" {
+			args.Set(k, v)
+		}
+	}
+	return nil
+}
+
+// pagingArgs returns the paging args of a facebook paging url, or "" when
+// there's no such page
+func pagingArgs(pagingURL string) string {
+	if pagingURL == "" {
+		return ""
+	}
+	u, err := url.Parse(pagingURL)
+	if err != nil {
+		return ""
+	}
+
+	args := u.Query()
+	paging := url.Values{}
+	for _, k := range pagingKeys {
+		if v := args.Get(k); v != "" {
+			paging.Set(k, v)
+		}
+	}
+	return paging.Encode()
+}
@@ -0,0 +1,80 @@
+package facebook
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-social/social/providers"
+)
+
+func init() {
+	providers.CursorKey = []byte("0123456789abcdef0123456789abcdef")
+}
+
+const nextURL = "https://graph.facebook.com/v2.11/me/feed?access_token=secret&fields=message&limit=25&until=1364587774&__paging_token=enc_abc"
+
+func TestPagingArgs(t *testing.T) {
+	if got, want := pagingArgs(nextURL), "__paging_token=enc_abc&until=1364587774"; got != want {
+		t.Errorf("expecting %q, got %q", want, got)
+	}
+	if got := pagingArgs(""); got != "" {
+		t.Errorf("expecting no paging args, got %q", got)
+	}
+}
+
+func TestPostsArgs(t *testing.T) {
+	query := providers.Query{
+		Limit: 10,
+		Since: time.Unix(1000, 0),
+		Until: time.Unix(2000, 0),
+	}
+	args, err := postsArgs(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Get("limit") != "10" || args.Get("since") != "1000" || args.Get("until") != "2000" || args.Get("fields") != postFields {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	// The next page args take over the bounds, but only the paging ones
+	query.UntilID = "until=1500&__paging_token=abc&fields=id&access_token=x"
+	args, err = postsArgs(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Get("until") != "1500" || args.Get("__paging_token") != "abc" || args.Get("since") != "1000" {
+		t.Errorf("unexpected paging args %v", args)
+	}
+	if args.Get("fields") != postFields || args.Get("access_token") != "" {
+		t.Errorf("expecting only the paging args to be set, got %v", args)
+	}
+
+	query.UntilID = "%zz"
+	if _, err := postsArgs(query); !errors.Is(err, providers.ErrInvalidQuery) {
+		t.Errorf("expecting ErrInvalidQuery, got %v", err)
+	}
+}
+
+func TestPagingCursor(t *testing.T) {
+	// The paging args go through the cursor token to the next request
+	query := providers.NewQuery(url.Values{"limit": {"25"}})
+	cursor := providers.NewCursor(query, "", pagingArgs(nextURL))
+
+	token, err := cursor.NextToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := providers.ParseQuery(url.Values{"cursor": {token}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := postsArgs(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Get("until") != "1364587774" || args.Get("__paging_token") != "enc_abc" || args.Get("limit") != "25" {
+		t.Errorf("unexpected next page args %v", args)
+	}
+}
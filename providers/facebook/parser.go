@@ -79,37 +79,56 @@ type FbPost struct {
 	Icon    string `json:"icon" facebook:"icon"`
 
 	Attachments struct {
-		Data []struct {
-			Description string `json:"description" facebook:"description"`
-			Title       string `json:"title" facebook:"title"`
-			Type        string `json:"type" facebook:"type"`
-			URL         string `json:"url" facebook:"url"`
-			Media       struct {
-				Image struct {
-					Width  int    `json:"width" facebook:"width"`
-					Height int    `json:"height" facebook:"height"`
-					Src    string `json:"src" facebook:"src"`
-				} `json:"image" facebook:"image"`
-			} `json:"media" facebook:"media"`
-			SubAttachments struct {
-				Data []struct {
-					Description string `json:"description" facebook:"description"`
-					Title       string `json:"title" facebook:"title"`
-					Type        string `json:"type" facebook:"type"`
-					URL         string `json:"url" facebook:"url"`
-					Media       struct {
-						Image struct {
-							Width  int    `json:"width" facebook:"width"`
-							Height int    `json:"height" facebook:"height"`
-							Src    string `json:"src" facebook:"src"`
-						} `json:"image" facebook:"image"`
-					} `json:"media" facebook:"media"`
-				} `json:"data" facebook:"data"`
-			} `json:"subattachments" facebook:"subattachments"`
-		} `json:"data" facebook:"data"`
+		Data []FbAttachment `json:"data" facebook:"data"`
 	} `json:"attachments" facebook:"attachments"`
 }
 
+type FbCommentsResponse struct {
+	Data    []FbComment `json:"data"`
+	Paging  Paging      `json:"paging"`
+	FbError fb.Error    `json:"error"`
+}
+
+type FbComment struct {
+	ID   string `json:"id" facebook:"id"`
+	From struct {
+		ID   string `json:"id" facebook:"id"`
+		Name string `json:"name" facebook:"name"`
+	} `json:"from" facebook:"from"`
+
+	Message      string `json:"message" facebook:"message"`
+	CreatedTime  string `json:"created_time" facebook:"created_time"`
+	LikeCount    int    `json:"like_count" facebook:"like_count"`
+	PermalinkURL string `json:"permalink_url" facebook:"permalink_url"`
+
+	// Parent comment of the replies to comments
+	Parent struct {
+		ID string `json:"id" facebook:"id"`
+	} `json:"parent" facebook:"parent"`
+
+	Attachment FbAttachment `json:"attachment" facebook:"attachment"`
+}
+
+type FbAttachment struct {
+	Description string `json:"description" facebook:"description"`
+	Title       string `json:"title" facebook:"title"`
+	Type        string `json:"type" facebook:"type"`
+	URL         string `json:"url" facebook:"url"`
+	Media       struct {
+		Image struct {
+			Width  int    `json:"width" facebook:"width"`
+			Height int    `json:"height" facebook:"height"`
+			Src    string `json:"src" facebook:"src"`
+		} `json:"image" facebook:"image"`
+		Source string `json:"source" facebook:"source"` // video file
+	} `json:"media" facebook:"media"`
+
+	// Photos of an album attachment
+	SubAttachments struct {
+		Data []FbAttachment `json:"data" facebook:"data"`
+	} `json:"subattachments" facebook:"subattachments"`
+}
+
 type FbResponseAccounts struct {
 	Data    []FbAccount `json:"data"`
 	Paging  Paging      `json:"paging"`
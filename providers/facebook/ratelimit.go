@@ -0,0 +1,85 @@
+package facebook
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-social/social/providers"
+)
+
+// usageProbe is how long calls are held back once a usage reaches 100%,
+// when facebook doesn't tell when access is regained. Usage is computed
+// over a rolling window, so it's checked again by letting a call through.
+const usageProbe = time.Minute
+
+type apiUsage struct {
+	CallCount    int `json:"call_count"`
+	TotalCPUTime int `json:"total_cputime"`
+	TotalTime    int `json:"total_time"`
+
+	// Business use case usage only, in minutes
+	EstimatedTimeToRegainAccess int `json:"estimated_time_to_regain_access"`
+}
+
+func (u apiUsage) percent() int {
+	p := u.CallCount
+	if u.TotalCPUTime > p {
+		p = u.TotalCPUTime
+	}
+	if u.TotalTime > p {
+		p = u.TotalTime
+	}
+	return p
+}
+
+// parseRateLimit returns the rate limit from the usage headers of a
+// facebook response. Facebook reports usage percentages, not call counts,
+// so the limit is 100 and the remaining calls are the percentage left of
+// the most used resource, and calls are only held back once it reaches
+// 100%: until the time to regain access, or usageProbe.
+// See: https://developers.facebook.com/docs/graph-api/overview/rate-limiting
+func parseRateLimit(resp *http.Response) (providers.RateLimit, bool) {
+	var usages []apiUsage
+
+	for _, h := range []string{"X-App-Usage", "X-Page-Usage"} {
+		var u apiUsage
+		if v := resp.Header.Get(h); v != "" && json.Unmarshal([]byte(v), &u) == nil {
+			usages = append(usages, u)
+		}
+	}
+	if v := resp.Header.Get("X-Business-Use-Case-Usage"); v != "" {
+		var buc map[string][]apiUsage
+		if json.Unmarshal([]byte(v), &buc) == nil {
+			for _, us := range buc {
+				usages = append(usages, us...)
+			}
+		}
+	}
+	if len(usages) == 0 {
+		return providers.RateLimit{}, false
+	}
+
+	used, regain := 0, 0
+	for _, u := range usages {
+		if p := u.percent(); p > used {
+			used = p
+		}
+		if u.EstimatedTimeToRegainAccess > regain {
+			regain = u.EstimatedTimeToRegainAccess
+		}
+	}
+
+	rl := providers.RateLimit{Limit: 100, Remaining: 100 - used}
+	if rl.Remaining > 0 {
+		// No reset time, so the calls left aren't counted down
+		return rl, true
+	}
+	rl.Remaining = 0
+	if regain > 0 {
+		rl.ResetAt = time.Now().Add(time.Duration(regain) * time.Minute)
+	} else {
+		rl.ResetAt = time.Now().Add(usageProbe)
+	}
+	return rl, true
+}
@@ -0,0 +1,38 @@
+package facebook
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := parseRateLimit(resp); ok {
+		t.Errorf("expecting no rate limit without usage headers")
+	}
+
+	// Usage percentages, with calls let through until 100%
+	resp.Header.Set("X-App-Usage", `{"call_count":28,"total_time":75,"total_cputime":10}`)
+	rl, ok := parseRateLimit(resp)
+	if !ok || rl.Limit != 100 || rl.Remaining != 25 || !rl.ResetAt.IsZero() {
+		t.Errorf("unexpected rate limit %+v", rl)
+	}
+	if rl.Exhausted(0, time.Now()) {
+		t.Errorf("expecting calls to be allowed below 100%%")
+	}
+
+	// Held back until the time to regain access
+	resp.Header.Set("X-Business-Use-Case-Usage", `{"1":[{"type":"pages","call_count":100,"estimated_time_to_regain_access":5}]}`)
+	rl, _ = parseRateLimit(resp)
+	if d := time.Until(rl.ResetAt); rl.Remaining != 0 || d < 4*time.Minute || d > 5*time.Minute {
+		t.Errorf("expecting a 5 minutes reset, got %+v", rl)
+	}
+
+	// Or probed again shortly
+	resp.Header = http.Header{"X-Page-Usage": {`{"call_count":120}`}}
+	rl, _ = parseRateLimit(resp)
+	if d := time.Until(rl.ResetAt); rl.Remaining != 0 || d <= 0 || d > usageProbe {
+		t.Errorf("expecting a probe reset, got %+v", rl)
+	}
+}
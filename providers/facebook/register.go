@@ -1,6 +1,10 @@
 package facebook
 
-import "github.com/go-social/social/providers"
+import (
+	"net/url"
+
+	"github.com/go-social/social/providers"
+)
 
 func Configure(appID string, appSecret string, oauthCallback string) {
 	AppID = appID
@@ -13,5 +17,27 @@ func init() {
 		Configure: Configure,
 		New:       New,
 		NewOAuth:  NewOAuth,
+		Capabilities: providers.Capabilities{
+			Feed:    true,
+			Posts:   true,
+			Publish: true,
+			Edit:    true,
+			Delete:  true,
+			Like:    true,
+			Share:   true,
+			Replies: true,
+		},
+		Limits: providers.PostLimits{
+			MaxLength: 63206,
+			MaxImages: maxImages,
+		},
+		EntityURLs: providers.EntityURLs{
+			Profile: func(username string) string {
+				return "https://facebook.com/" + url.PathEscape(username)
+			},
+			Hashtag: func(tag string) string {
+				return "https://facebook.com/hashtag/" + url.PathEscape(tag)
+			},
+		},
 	})
 }
@@ -0,0 +1,79 @@
+package facebook
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+var commentFields = strings.Join([]string{
+	"attachment",
+	"created_time",
+	"from",
+	"id",
+	"like_count",
+	"message",
+	"parent{id}",
+	"permalink_url",
+}, ",")
+
+// GetReplies returns the comments of a post, or the replies to a comment
+// Network docs: https://developers.facebook.com/docs/graph-api/reference/v2.11/object/comments
+func (p *Provider) GetReplies(ctx context.Context, postID string, query providers.Query) (social.Posts, *providers.Cursor, error) {
+	return p.getComments(postID, "toplevel", "reverse_chronological", query)
+}
+
+// GetThread returns a post with all its comments, and the replies to them
+func (p *Provider) GetThread(ctx context.Context, postID string, query providers.Query) (*social.Post, *providers.Cursor, error) {
+	args := url.Values{}
+	args.Set("fields", postFields)
+	resp, err := p.api.Get("/"+postID, getFbParams(args))
+	if _, err = getActualResponseAndError(resp, err); err != nil {
+		return nil, nil, p.apiError(err)
+	}
+	var fbPost FbPost
+	if err := resp.Decode(&fbPost); err != nil {
+		return nil, nil, providers.ErrUnknown.Err(err)
+	}
+	root := (&Mapper{}).BuildPost(fbPost)
+	if root == nil {
+		return nil, nil, providers.ErrPostNotFound
+	}
+
+	// The stream filter returns the replies to comments as well, with
+	// their parent comment
+	comments, cursor, err := p.getComments(postID, "stream", "chronological", query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return providers.BuildThread(root, comments), cursor, nil
+}
+
+func (p *Provider) getComments(postID string, filter string, order string, query providers.Query) (social.Posts, *providers.Cursor, error) {
+	args := url.Values{}
+	args.Set("fields", commentFields)
+	args.Set("limit", strconv.Itoa(query.Limit))
+	args.Set("filter", filter)
+	args.Set("order", order)
+	if err := setPagingArgs(args, query); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := p.api.Get("/"+postID+"/comments", getFbParams(args))
+	if _, err = getActualResponseAndError(resp, err); err != nil {
+		return nil, nil, p.apiError(err)
+	}
+	var fbResponse FbCommentsResponse
+	if err := resp.Decode(&fbResponse); err != nil {
+		return nil, nil, providers.ErrUnknown.Err(err)
+	}
+
+	posts := (&Mapper{}).BuildComments(fbResponse.Data, postID)
+	cursor := providers.NewCursor(query, pagingArgs(fbResponse.Paging.Previous), pagingArgs(fbResponse.Paging.Next))
+
+	return posts, cursor, nil
+}
@@ -0,0 +1,362 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-social/social"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxFilterPages is the number of provider pages fetched at most to fill
+// up a filtered query to its limit
+var MaxFilterPages = 5
+
+type filterTermKind int
+
+const (
+	filterWord filterTermKind = iota
+	filterPhrase
+	filterUser
+	filterTag
+)
+
+type filterTerm struct {
+	kind  filterTermKind
+	value string // folded
+}
+
+// PostFilter is a parsed second-pass keywords filter, see Query.Filter.
+//
+// Filters are space separated terms which must all match a post: words,
+// "quoted phrases", @usernames and #tags. A term prefixed with "-" must
+// not match. Matching is case and diacritics insensitive.
+type PostFilter struct {
+	include []filterTerm
+	exclude []filterTerm
+}
+
+// NewPostFilter parses a filter, and returns nil for an empty filter
+func NewPostFilter(filter string) *PostFilter {
+	f := &PostFilter{}
+
+	for _, tok := range splitTerms(filter) {
+		negate := false
+		if len(tok) > 1 && tok[0] == '-' {
+			negate = true
+			tok = tok[1:]
+		}
+
+		term, ok := newFilterTerm(tok)
+		if !ok {
+			continue
+		}
+
+		if negate {
+			f.exclude = append(f.exclude, term)
+		} else {
+			f.include = append(f.include, term)
+		}
+	}
+
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return nil
+	}
+	return f
+}
+
+// newFilterTerm parses a filter term, without its negation
+func newFilterTerm(tok string) (filterTerm, bool) {
+	var term filterTerm
+	switch {
+	case len(tok) > 2 && tok[0] == '"':
+		term = filterTerm{filterPhrase, strings.Join(words(fold(strings.Trim(tok, `"`))), " ")}
+	case len(tok) > 1 && tok[0] == '@':
+		term = filterTerm{filterUser, fold(tok[1:])}
+	case len(tok) > 1 && tok[0] == '#':
+		term = filterTerm{filterTag, fold(tok[1:])}
+	default:
+		// Words with punctuation, ie. "e-mail", match as a phrase
+		ws := words(fold(tok))
+		term = filterTerm{filterWord, strings.Join(ws, " ")}
+		if len(ws) > 1 {
+			term.kind = filterPhrase
+		}
+	}
+	return term, term.value != ""
+}
+
+// Match reports whether the post contents, tags or links match the filter
+func (f *PostFilter) Match(post *social.Post) bool {
+	if f == nil {
+		return true
+	}
+	doc := newFilterDoc(post)
+	for _, t := range f.include {
+		if !doc.match(t) {
+			return false
+		}
+	}
+	for _, t := range f.exclude {
+		if doc.match(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterPosts returns the posts matching the filter
+func FilterPosts(posts social.Posts, f *PostFilter) social.Posts {
+	if f == nil {
+		return posts
+	}
+	var res social.Posts
+	for _, p := range posts {
+		if f.Match(p) {
+			res.Add(p)
+		}
+	}
+	return res
+}
+
+// FilteredPage fetches pages until query.Limit posts matching the query
+// Filter, Since, Until and Lang are found, or the results are exhausted,
+// or MaxFilterPages is reached. Provider pages aren't cut, as their ids
+// aren't all usable as cursors, so there may be more posts than the limit:
+// the cursor returned continues after the last page fetched.
+//
+// When a page after the first fails, the posts found so far are returned
+// with the error, and a cursor continuing with the page which failed.
+//
+// Posts are expected newest first, as returned by all providers, so paging
+// stops at the first post published before query.Since.
+func FilteredPage(fetch func(query Query) (social.Posts, *Cursor, error), query Query) (social.Posts, *Cursor, error) {
+	m := newQueryMatcher(query)
+	if m == nil {
+		return fetch(query)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultNumResults
+	}
+
+	var posts social.Posts
+	var prev, next *Query
+
+	q := query
+	for page := 0; page < MaxFilterPages; page++ {
+		ps, cursor, err := fetch(q)
+		if err != nil {
+			if page == 0 {
+				return nil, nil, err
+			}
+			return posts, &Cursor{Prev: prev, Next: &q}, err
+		}
+		if cursor != nil && page == 0 {
+			prev = cursor.Prev
+		}
+
+		for _, p := range ps {
+			if m.Match(p) {
+				posts.Add(p)
+			}
+		}
+
+		// Provider pages may be empty, ie. filtered natively, with more
+		// results after them
+		next = nil
+		if (len(ps) > 0 && m.pastSince(ps[len(ps)-1])) || !cursor.HasNext() || cursor.Next.UntilID == q.UntilID {
+			break
+		}
+		next = cursor.Next
+		if len(posts) >= limit {
+			break
+		}
+		q = *next
+	}
+
+	return posts, &Cursor{Prev: prev, Next: next}, nil
+}
+
+// queryMatcher matches posts against the query Filter, Since, Until and
+// Lang, for the providers which can't apply them natively
+type queryMatcher struct {
+	filter *PostFilter
+	since  time.Time
+	until  time.Time
+	lang   string
+}
+
+func newQueryMatcher(query Query) *queryMatcher {
+	m := &queryMatcher{
+		filter: NewPostFilter(query.Filter),
+		since:  query.Since,
+		until:  query.Until,
+		lang:   query.Lang,
+	}
+	if m.filter == nil && m.since.IsZero() && m.until.IsZero() && m.lang == "" {
+		return nil
+	}
+	return m
+}
+
+func (m *queryMatcher) Match(post *social.Post) bool {
+	if post.PublishedAt != nil {
+		if !m.since.IsZero() && post.PublishedAt.Before(m.since) {
+			return false
+		}
+		if !m.until.IsZero() && !post.PublishedAt.Before(m.until) {
+			return false
+		}
+	}
+	// Posts of an unknown language are kept, ie. facebook doesn't tell
+	if m.lang != "" && post.Lang != "" && !strings.EqualFold(post.Lang, m.lang) {
+		return false
+	}
+	return m.filter.Match(post)
+}
+
+// pastSince reports whether the post is older than the query Since
+func (m *queryMatcher) pastSince(post *social.Post) bool {
+	return !m.since.IsZero() && post.PublishedAt != nil && post.PublishedAt.Before(m.since)
+}
+
+// filterSession applies the second-pass Query.Filter, and the Since, Until
+// and Lang bounds, to the results of any provider
+type filterSession struct {
+	ProviderSession
+}
+
+func (s *filterSession) Search(query Query) (social.Posts, *Cursor, error) {
+	return FilteredPage(s.ProviderSession.Search, query)
+}
+
+func (s *filterSession) GetFeed(query Query) (social.Posts, *Cursor, error) {
+	return FilteredPage(s.ProviderSession.GetFeed, query)
+}
+
+func (s *filterSession) GetPosts(query Query) (social.Posts, *Cursor, error) {
+	return FilteredPage(s.ProviderSession.GetPosts, query)
+}
+
+func (s *filterSession) GetReplies(ctx context.Context, postID string, query Query) (social.Posts, *Cursor, error) {
+	return FilteredPage(func(query Query) (social.Posts, *Cursor, error) {
+		return s.ProviderSession.GetReplies(ctx, postID, query)
+	}, query)
+}
+
+// filterDoc is the folded, tokenized text of a post to match terms against
+type filterDoc struct {
+	text     string // words joined by single spaces
+	words    map[string]bool
+	tags     map[string]bool
+	mentions map[string]bool
+}
+
+func newFilterDoc(post *social.Post) *filterDoc {
+	d := &filterDoc{
+		words:    map[string]bool{},
+		tags:     map[string]bool{},
+		mentions: map[string]bool{},
+	}
+
+	contents := fold(post.Contents)
+	d.text = strings.Join(words(contents), " ")
+	d.addTokens(contents)
+
+	for _, tag := range post.Tags {
+		tag = fold(strings.TrimPrefix(tag, "#"))
+		d.tags[tag] = true
+		d.words[tag] = true
+	}
+	for _, link := range post.Links {
+		for _, w := range words(fold(link)) {
+			d.words[w] = true
+		}
+	}
+	for _, mention := range post.Mentions {
+		d.mentions[fold(mention)] = true
+	}
+	if post.Author.Username != "" {
+		d.mentions[fold(post.Author.Username)] = true
+	}
+
+	return d
+}
+
+func (d *filterDoc) addTokens(s string) {
+	for _, tok := range strings.FieldsFunc(s, unicode.IsSpace) {
+		prefix := tok[0]
+		for _, w := range words(tok) {
+			d.words[w] = true
+			switch prefix {
+			case '#':
+				d.tags[w] = true
+			case '@':
+				d.mentions[w] = true
+			}
+			prefix = 0
+		}
+	}
+}
+
+func (d *filterDoc) match(t filterTerm) bool {
+	switch t.kind {
+	case filterPhrase:
+		return strings.Contains(" "+d.text+" ", " "+t.value+" ")
+	case filterUser:
+		return d.mentions[t.value]
+	case filterTag:
+		return d.tags[t.value]
+	default:
+		return d.words[t.value]
+	}
+}
+
+// splitTerms splits a filter on spaces, keeping quoted phrases together
+func splitTerms(s string) []string {
+	var terms []string
+	var cur strings.Builder
+	quoted := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if cur.Len() > 0 {
+				terms = append(terms, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		terms = append(terms, cur.String())
+	}
+	return terms
+}
+
+// words splits s into words of letters, digits and underscores
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// fold lower cases s and strips diacritics, ie. "Café" -> "cafe"
+func fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
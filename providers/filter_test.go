@@ -0,0 +1,59 @@
+package providers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-social/social"
+)
+
+func TestFilteredPageCursor(t *testing.T) {
+	// Provider pages of 3 posts, with opaque paging args as cursors
+	pages := map[string]social.Posts{
+		"":       {{ID: "9", Contents: "go"}, {ID: "8", Contents: "rust"}, {ID: "7", Contents: "go"}},
+		"page=2": {{ID: "6", Contents: "rust"}, {ID: "5", Contents: "rust"}, {ID: "4", Contents: "rust"}},
+		"page=3": {{ID: "3", Contents: "go"}, {ID: "2", Contents: "go"}, {ID: "1", Contents: "go"}},
+	}
+	nexts := map[string]string{"": "page=2", "page=2": "page=3"}
+	fetch := func(query Query) (social.Posts, *Cursor, error) {
+		return pages[query.UntilID], NewCursor(query, "", nexts[query.UntilID]), nil
+	}
+
+	posts, cursor, err := FilteredPage(fetch, Query{Limit: 3, Filter: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last page isn't cut, and the cursor is the provider one
+	if len(posts) != 5 || posts[2].ID != "3" {
+		t.Errorf("unexpected posts %v", posts)
+	}
+	if cursor.HasNext() {
+		t.Errorf("expecting no next page, got %v", cursor.Next.UntilID)
+	}
+
+	posts, cursor, err = FilteredPage(fetch, Query{Limit: 1, Filter: "rust"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || cursor.Next.UntilID != "page=2" {
+		t.Errorf("unexpected page %v, next %v", posts, cursor.Next)
+	}
+}
+
+func TestFilteredPageError(t *testing.T) {
+	fail := errors.New("down")
+	fetch := func(query Query) (social.Posts, *Cursor, error) {
+		if query.UntilID == "" {
+			return social.Posts{{ID: "2", Contents: "go"}}, NewCursor(query, "", "page=2"), nil
+		}
+		return nil, nil, fail
+	}
+
+	posts, cursor, err := FilteredPage(fetch, Query{Limit: 5, Filter: "go"})
+	if !errors.Is(err, fail) {
+		t.Errorf("expecting the page error, got %v", err)
+	}
+	if len(posts) != 1 || cursor.Next.UntilID != "page=2" {
+		t.Errorf("expecting the posts so far and the failed page cursor, got %v, %v", posts, cursor.Next)
+	}
+}
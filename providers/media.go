@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-social/social"
+)
+
+// MediaUpload is an image, video or gif file to upload and attach to a post,
+// see PostRequest
+type MediaUpload struct {
+	// Type of the media, detected from the ContentType when empty
+	Type social.MediaType
+
+	// ContentType is the mime type of the file, ie. "image/png" or
+	// "video/mp4", detected from the Filename or Data when empty
+	ContentType string
+
+	Filename string
+	Data     []byte
+	AltText  string
+}
+
+// Prepare fills in the ContentType and Type of the upload, and returns
+// ErrInvalidAsset when the file is empty or not an image, video or gif
+func (m *MediaUpload) Prepare() error {
+	if len(m.Data) == 0 {
+		return ErrInvalidAsset.Err(fmt.Errorf("empty media file %q", m.Filename))
+	}
+	if m.ContentType == "" && m.Filename != "" {
+		m.ContentType = mime.TypeByExtension(path.Ext(m.Filename))
+	}
+	if m.ContentType == "" {
+		m.ContentType = http.DetectContentType(m.Data)
+	}
+	if m.Type == "" {
+		m.Type = MediaTypeOf(m.ContentType)
+	}
+	if m.Type == "" {
+		return ErrInvalidAsset.Err(fmt.Errorf("unsupported media type %q", m.ContentType))
+	}
+	if m.Filename == "" {
+		m.Filename = "media"
+		if exts, _ := mime.ExtensionsByType(m.ContentType); len(exts) > 0 {
+			m.Filename += exts[0]
+		}
+	}
+	return nil
+}
+
+// MediaTypeOf returns the media type of a mime type, or "" when it's not
+// an image, video or gif
+func MediaTypeOf(contentType string) social.MediaType {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "image/gif":
+		return social.MediaGIF
+	case strings.HasPrefix(mediaType, "image/"):
+		return social.MediaImage
+	case strings.HasPrefix(mediaType, "video/"):
+		return social.MediaVideo
+	}
+	return ""
+}
+
+// PrepareMedia prepares the uploads of a post and checks the combination
+// of media the providers accept: up to maxImages images, or any number when
+// 0, or a single video or gif
+func PrepareMedia(media []*MediaUpload, maxImages int) error {
+	images := 0
+	for _, m := range media {
+		if err := m.Prepare(); err != nil {
+			return err
+		}
+		if m.Type != social.MediaImage {
+			if len(media) > 1 {
+				return ErrInvalidAsset.Err(fmt.Errorf("a %s can't be posted with other media", m.Type))
+			}
+			continue
+		}
+		images++
+	}
+	if maxImages > 0 && images > maxImages {
+		return ErrInvalidAsset.Err(fmt.Errorf("at most %d images can be posted, got %d", maxImages, images))
+	}
+	return nil
+}
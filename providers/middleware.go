@@ -0,0 +1,92 @@
+package providers
+
+// Middleware wraps a provider session, ie. to add logging, metrics, caching
+// or retries to all the providers. Middlewares embed the ProviderSession
+// they wrap, and override the methods they're concerned with.
+type Middleware func(ProviderSession) ProviderSession
+
+// Middlewares registered with Use, wrapping every session
+var middlewares []Middleware
+
+// Use registers middlewares wrapping every session returned by NewSession,
+// after the ones already registered. Like Register, it's meant to be
+// called on setup.
+func Use(mws ...Middleware) {
+	middlewares = append(middlewares, mws...)
+}
+
+// Chain returns a middleware applying the middlewares in order, the first
+// one being the outermost
+func Chain(mws ...Middleware) Middleware {
+	return func(s ProviderSession) ProviderSession {
+		for i := len(mws) - 1; i >= 0; i-- {
+			s = mws[i](s)
+		}
+		return s
+	}
+}
+
+// SessionOption configures a session returned by NewSession
+type SessionOption func(*sessionOptions)
+
+type sessionOptions struct {
+	middlewares []Middleware
+	noDefaults  bool
+}
+
+// WithMiddleware wraps the session with the middlewares, outside of the
+// ones registered with Use
+func WithMiddleware(mws ...Middleware) SessionOption {
+	return func(o *sessionOptions) {
+		o.middlewares = append(o.middlewares, mws...)
+	}
+}
+
+// WithoutDefaultMiddleware leaves out the default middlewares of the
+// session, see DefaultMiddleware
+func WithoutDefaultMiddleware() SessionOption {
+	return func(o *sessionOptions) {
+		o.noDefaults = true
+	}
+}
+
+// DefaultMiddleware returns the middlewares wrapping the sessions closest
+// to the provider: the Query.Filter second-pass filter, retries with
+// DefaultRetryPolicy and the Circuits circuit breakers
+func DefaultMiddleware() []Middleware {
+	return []Middleware{
+		FilterMiddleware(),
+		RetryMiddleware(DefaultRetryPolicy),
+		BreakerMiddleware(Circuits),
+	}
+}
+
+// FilterMiddleware applies the second-pass Query.Filter, and the Since,
+// Until and Lang bounds, to the session results
+func FilterMiddleware() Middleware {
+	return func(s ProviderSession) ProviderSession {
+		return &filterSession{s}
+	}
+}
+
+// RetryMiddleware retries the session reads, see WithRetry
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	return func(s ProviderSession) ProviderSession {
+		return WithRetry(s, policy)
+	}
+}
+
+// BreakerMiddleware fails the session calls fast while the provider is
+// down, see WithCircuitBreaker
+func BreakerMiddleware(breakers *CircuitBreakers) Middleware {
+	return func(s ProviderSession) ProviderSession {
+		return WithCircuitBreaker(s, breakers)
+	}
+}
+
+// CacheMiddleware caches the session read results, see WithCache
+func CacheMiddleware(cache Cache, ttls CacheTTLs) Middleware {
+	return func(s ProviderSession) ProviderSession {
+		return WithCache(s, cache, ttls)
+	}
+}
@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-social/social"
+)
+
+// traceSession records its name on the searches going through it
+type traceSession struct {
+	ProviderSession
+	name  string
+	trace *[]string
+}
+
+func (s *traceSession) Search(query Query) (social.Posts, *Cursor, error) {
+	*s.trace = append(*s.trace, s.name)
+	return s.ProviderSession.Search(query)
+}
+
+func traceMiddleware(name string, trace *[]string) Middleware {
+	return func(s ProviderSession) ProviderSession {
+		return &traceSession{ProviderSession: s, name: name, trace: trace}
+	}
+}
+
+// layers returns the middlewares wrapping a session, outermost first
+func layers(s ProviderSession) []string {
+	var names []string
+	for {
+		switch v := s.(type) {
+		case *cursorSession:
+			names, s = append(names, "cursor"), v.ProviderSession
+		case *filterSession:
+			names, s = append(names, "filter"), v.ProviderSession
+		case *retrySession:
+			names, s = append(names, "retry"), v.ProviderSession
+		case *breakerSession:
+			names, s = append(names, "breaker"), v.ProviderSession
+		case *traceSession:
+			names, s = append(names, v.name), v.ProviderSession
+		default:
+			return append(names, "provider")
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var trace []string
+	s := Chain(traceMiddleware("a", &trace), traceMiddleware("b", &trace))(newPagedSession("chain", 1))
+	if got, want := layers(s), []string{"a", "b", "provider"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v, got %v", want, got)
+	}
+	s.Search(Query{})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(trace, want) {
+		t.Errorf("expecting the calls through %v, got %v", want, trace)
+	}
+
+	if s := Chain()(newPagedSession("chain", 1)); !reflect.DeepEqual(layers(s), []string{"provider"}) {
+		t.Errorf("expecting an empty chain to leave the session as is")
+	}
+}
+
+func TestNewSessionMiddleware(t *testing.T) {
+	Registry["middleware"] = &Provider{
+		New: func(ctx context.Context, creds social.Credentials) (ProviderSession, error) {
+			return newPagedSession("middleware", 3), nil
+		},
+	}
+	registered := middlewares
+	defer func() {
+		delete(Registry, "middleware")
+		middlewares = registered
+	}()
+
+	var trace []string
+	Use(traceMiddleware("use", &trace))
+
+	// The session middlewares, then the registered ones, then the defaults
+	// closest to the provider, with the cursors bound outermost
+	s, err := NewSession(context.Background(), "middleware", nil, WithMiddleware(traceMiddleware("with", &trace)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := layers(s), []string{"cursor", "with", "use", "filter", "retry", "breaker", "provider"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v, got %v", want, got)
+	}
+	if _, _, err := s.Search(Query{Limit: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"with", "use"}; !reflect.DeepEqual(trace, want) {
+		t.Errorf("expecting the calls through %v, got %v", want, trace)
+	}
+
+	// Opting out of the defaults
+	s, err = NewSession(context.Background(), "middleware", nil, WithoutDefaultMiddleware())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := layers(s), []string{"cursor", "use", "provider"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v without the defaults, got %v", want, got)
+	}
+
+	if got := len(DefaultMiddleware()); got != 3 {
+		t.Errorf("expecting 3 default middlewares, got %d", got)
+	}
+}
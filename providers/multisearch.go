@@ -0,0 +1,281 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-social/social"
+)
+
+// MultiSearchResult holds the merged results of a search across multiple
+// providers, and the errors of the providers which failed
+type MultiSearchResult struct {
+	Posts  social.Posts
+	Cursor *MultiCursor
+	Errors map[string]error // by provider id
+}
+
+// MultiCursor holds the position of each provider in a multi search. A
+// provider missing from the Next queries has no more results.
+type MultiCursor struct {
+	Next map[string]*Query
+	Prev map[string]*Query
+}
+
+const multiCursorPrefix = "p:"
+
+// NextToken returns the opaque token of the next page, or an empty string
+// when all the providers are exhausted
+func (c *MultiCursor) NextToken() (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	return encodeMultiCursor(c.Next)
+}
+
+// PrevToken returns the opaque token of the previous page
+func (c *MultiCursor) PrevToken() (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	return encodeMultiCursor(c.Prev)
+}
+
+func encodeMultiCursor(queries map[string]*Query) (string, error) {
+	args := url.Values{}
+	for id, q := range queries {
+		args.Set(multiCursorPrefix+id, cursorArgs(*q).Encode())
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+	return encodeCursorArgs(args)
+}
+
+// decodeMultiCursor returns the per-provider queries of a multi cursor
+// token, or nil when the query isn't paging a multi search
+func decodeMultiCursor(query Query) (map[string]Query, error) {
+	token := query.Params.Get("cursor")
+	if token == "" {
+		return nil, nil
+	}
+	args, err := DecodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries map[string]Query
+	for k := range args {
+		if !strings.HasPrefix(k, multiCursorPrefix) {
+			continue
+		}
+		pargs, err := url.ParseQuery(args.Get(k))
+		if err != nil {
+			return nil, ErrInvalidCursor.Err(err)
+		}
+		if queries == nil {
+			queries = map[string]Query{}
+		}
+		queries[strings.TrimPrefix(k, multiCursorPrefix)] = NewQuery(pargs)
+	}
+	return queries, nil
+}
+
+// multiAfterKey is the arg of a provider position in a multi cursor, set
+// when its page was cut to the query limit: the id of the last post of the
+// page which was returned. The page is fetched again, from after that post.
+const multiAfterKey = "multi_after"
+
+// MultiSearch runs the query on each provider session concurrently and
+// merges the results, up to query.Limit posts. Posts are sorted by publish
+// date, or by engagement when query.Sort is "popular". An error is only
+// returned when the query is invalid or all the providers failed;
+// otherwise the failures are reported per provider in the result.
+//
+// The result cursor tokens page each provider independently, from the
+// last post it contributed, and are passed back as the `cursor` query
+// argument.
+func MultiSearch(ctx context.Context, sessions []ProviderSession, query Query) (*MultiSearchResult, error) {
+	positions, err := decodeMultiCursor(query)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := map[string]Query{}
+	for _, s := range sessions {
+		id := s.ID()
+		if _, ok := queries[id]; ok {
+			return nil, ErrInvalidQuery.Err(fmt.Errorf("duplicate provider session %q", id))
+		}
+		q := query
+		if positions != nil {
+			// A provider missing from the cursor is already exhausted
+			var ok bool
+			if q, ok = positions[id]; !ok {
+				continue
+			}
+		}
+		q.Params = cloneParams(q.Params)
+		queries[id] = q
+	}
+
+	type searchResult struct {
+		id     string
+		query  Query
+		posts  social.Posts
+		cursor *Cursor
+		err    error
+	}
+
+	results := make(chan searchResult, len(queries))
+	var wg sync.WaitGroup
+	for _, s := range sessions {
+		q, ok := queries[s.ID()]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(s ProviderSession, q Query) {
+			defer wg.Done()
+			sq := q
+			sq.Params = cloneParams(q.Params)
+			sq.Params.Del(multiAfterKey)
+			posts, cursor, err := s.Search(sq)
+			results <- searchResult{s.ID(), q, skipPosts(posts, q.Params.Get(multiAfterKey)), cursor, err}
+		}(s, q)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	res := &MultiSearchResult{
+		Cursor: &MultiCursor{Next: map[string]*Query{}, Prev: map[string]*Query{}},
+		Errors: map[string]error{},
+	}
+	total := len(queries)
+	pending := total
+	var pages []searchResult
+
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			for id, q := range queries {
+				q := q
+				res.Errors[id] = ctx.Err()
+				res.Cursor.Next[id] = &q
+			}
+			pending = 0
+
+		case r := <-results:
+			delete(queries, r.id)
+			pending--
+			if r.err != nil {
+				// Keep the provider position, to try it again on the next page
+				res.Errors[r.id] = r.err
+				res.Cursor.Next[r.id] = &r.query
+				continue
+			}
+			pages = append(pages, r)
+			res.Posts.Add(r.posts...)
+		}
+	}
+
+	if total > 0 && len(res.Errors) == total {
+		for _, s := range sessions {
+			if err, ok := res.Errors[s.ID()]; ok {
+				return nil, err
+			}
+		}
+	}
+
+	SortPosts(res.Posts, query.Sort)
+	if len(res.Posts) > query.Limit {
+		res.Posts = res.Posts[:query.Limit]
+	}
+
+	// The last post each provider contributed
+	last := map[*social.Post]bool{}
+	for _, p := range res.Posts {
+		last[p] = true
+	}
+	for _, r := range pages {
+		after, cut := "", false
+		for _, p := range r.posts {
+			if last[p] {
+				after = p.ID
+			} else {
+				cut = true
+			}
+		}
+
+		switch {
+		case cut:
+			// The rest of the page is fetched again, from after the last
+			// post returned, or from the same position
+			q := r.query
+			q.Params = cloneParams(q.Params)
+			if after != "" {
+				q.Params.Set(multiAfterKey, after)
+			}
+			res.Cursor.Next[r.id] = &q
+		case r.cursor.HasNext() && r.cursor.Next.UntilID != r.query.UntilID:
+			// Kept past empty pages, ie. filtered empty, while the cursor
+			// advances
+			res.Cursor.Next[r.id] = r.cursor.Next
+		}
+		if r.cursor != nil && r.cursor.Prev != nil && r.cursor.Prev.SinceID != "" {
+			res.Cursor.Prev[r.id] = r.cursor.Prev
+		}
+	}
+	return res, nil
+}
+
+// skipPosts returns the posts after the one of the id, or all the posts
+// when it isn't found
+func skipPosts(posts social.Posts, id string) social.Posts {
+	if id == "" {
+		return posts
+	}
+	for i, p := range posts {
+		if p.ID == id {
+			return posts[i+1:]
+		}
+	}
+	return posts
+}
+
+// SortPosts sorts posts by publish date, most recent first, or by
+// engagement score when sort is "popular"
+func SortPosts(posts social.Posts, order string) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		a, b := posts[i], posts[j]
+		if order == "popular" {
+			if sa, sb := EngagementScore(a), EngagementScore(b); sa != sb {
+				return sa > sb
+			}
+		}
+		if a.PublishedAt == nil || b.PublishedAt == nil {
+			return a.PublishedAt != nil
+		}
+		return a.PublishedAt.After(*b.PublishedAt)
+	})
+}
+
+// EngagementScore ranks a post across providers, shares weigh more than
+// likes as they expose the post to a new audience
+func EngagementScore(post *social.Post) int64 {
+	return int64(post.NumLikes) + 2*int64(post.NumShares)
+}
+
+func cloneParams(params url.Values) url.Values {
+	c := url.Values{}
+	for k, v := range params {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
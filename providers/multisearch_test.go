@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+// timedSession is a paged session of posts published at the given minutes,
+// newest first, with the minutes as ids
+func timedSession(id string, minutes ...int) *pagedSession {
+	s := &pagedSession{id: id}
+	base := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, m := range minutes {
+		published := base.Add(time.Duration(m) * time.Minute)
+		s.posts.Add(&social.Post{ID: strconv.Itoa(m), Provider: id, PublishedAt: &published})
+	}
+	return s
+}
+
+// failingSession is a session whose searches fail
+type failingSession struct {
+	ProviderSession
+	id  string
+	err error
+}
+
+func (s *failingSession) ID() string {
+	return s.id
+}
+
+func (s *failingSession) Search(query Query) (social.Posts, *Cursor, error) {
+	return nil, nil, s.err
+}
+
+// filteredSession is a paged session whose first page was filtered empty
+type filteredSession struct {
+	*pagedSession
+}
+
+func (s *filteredSession) Search(query Query) (social.Posts, *Cursor, error) {
+	posts, cursor, err := s.pagedSession.Search(query)
+	if query.UntilID == "" {
+		return nil, cursor, err
+	}
+	return posts, cursor, err
+}
+
+func ids(posts social.Posts) string {
+	var s []string
+	for _, p := range posts {
+		s = append(s, p.Provider+p.ID)
+	}
+	return strings.Join(s, ",")
+}
+
+// multiPages pages a multi search through its tokens, and returns the ids
+// of each page
+func multiPages(t *testing.T, sessions []ProviderSession, args url.Values) []string {
+	t.Helper()
+	var pages []string
+	for i := 0; i < 10; i++ {
+		query, err := ParseQuery(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := MultiSearch(context.Background(), sessions, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, ids(res.Posts))
+		token, err := res.Cursor.NextToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token == "" {
+			return pages
+		}
+		args = url.Values{"cursor": {token}, "limit": args["limit"]}
+	}
+	t.Fatalf("expecting the paging to end, got %q", pages)
+	return nil
+}
+
+func TestMultiSearchMerge(t *testing.T) {
+	a, b := timedSession("a", 5, 3, 1), timedSession("b", 4, 2)
+	res, err := MultiSearch(context.Background(), []ProviderSession{a, b}, Query{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res.Posts); got != "a5,b4,a3,b2,a1" {
+		t.Errorf("expecting the posts by date, got %s", got)
+	}
+	if len(res.Cursor.Next) != 0 || len(res.Errors) != 0 {
+		t.Errorf("expecting the providers exhausted, got %v %v", res.Cursor.Next, res.Errors)
+	}
+
+	// By engagement
+	a.posts[2].NumLikes = 10
+	b.posts[1].NumShares = 4
+	res, _ = MultiSearch(context.Background(), []ProviderSession{a, b}, Query{Limit: 10, Sort: "popular"})
+	if got := ids(res.Posts); got != "a1,b2,a5,b4,a3" {
+		t.Errorf("expecting the posts by engagement, got %s", got)
+	}
+
+	if _, err := MultiSearch(context.Background(), []ProviderSession{a, a}, Query{Limit: 10}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expecting ErrInvalidQuery for duplicate sessions, got %v", err)
+	}
+}
+
+func TestMultiSearchErrors(t *testing.T) {
+	a := timedSession("a", 3, 2, 1)
+	failing := &failingSession{id: "f", err: ErrProviderDown}
+
+	res, err := MultiSearch(context.Background(), []ProviderSession{a, failing}, Query{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids(res.Posts) != "a3,a2,a1" || !errors.Is(res.Errors["f"], ErrProviderDown) {
+		t.Errorf("expecting the failure reported per provider, got %s %v", ids(res.Posts), res.Errors)
+	}
+	if _, ok := res.Cursor.Next["f"]; !ok {
+		t.Errorf("expecting the failed provider to be tried again on the next page")
+	}
+
+	// Only all the providers failing is an error
+	_, err = MultiSearch(context.Background(), []ProviderSession{failing}, Query{Limit: 10})
+	if !errors.Is(err, ErrProviderDown) {
+		t.Errorf("expecting ErrProviderDown, got %v", err)
+	}
+}
+
+func TestMultiSearchPaging(t *testing.T) {
+	// Pages cut to the limit, each provider resuming after the last post
+	// it contributed
+	a, b := timedSession("a", 6, 4, 2), timedSession("b", 5, 3, 1)
+	pages := multiPages(t, []ProviderSession{a, b}, url.Values{"limit": {"2"}})
+	if got := strings.Join(pages, " "); got != "a6,b5 a4,b3 a2,b1" {
+		t.Errorf("unexpected pages %q", got)
+	}
+
+	a, b = timedSession("a", 9, 8, 7, 1), timedSession("b", 6, 5)
+	pages = multiPages(t, []ProviderSession{a, b}, url.Values{"limit": {"3"}})
+	if got := strings.Join(pages, " "); got != "a9,a8,a7 b6,b5,a1" {
+		t.Errorf("unexpected pages %q", got)
+	}
+}
+
+func TestMultiSearchEmptyPage(t *testing.T) {
+	// A provider page filtered empty keeps the provider while its cursor
+	// advances
+	a := &filteredSession{timedSession("a", 6, 4, 2, 1)}
+	b := timedSession("b", 5)
+	pages := multiPages(t, []ProviderSession{a, b}, url.Values{"limit": {"2"}})
+	if got := strings.Join(pages, " "); got != "b5 a2,a1" {
+		t.Errorf("unexpected pages %q", got)
+	}
+}
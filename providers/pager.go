@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+// DefaultRateLimitWait is how long a Pager waits after hitting a rate
+// limit, when the provider doesn't tell us when it resets
+const DefaultRateLimitWait = 15 * time.Minute
+
+// PageFunc fetches a page of results for a query, ie. any of the
+// ProviderSession list methods
+type PageFunc[T any] func(query Query) ([]T, *Cursor, error)
+
+type PagerOptions struct {
+	MaxItems int // stop after this many items, 0 for no limit
+	MaxPages int // stop after this many pages, 0 for no limit
+
+	// Wait and try the page again when hitting a rate limit, instead of
+	// returning ErrHitRateLimit. The pager waits until the limit resets,
+	// or RateLimitWait when the provider doesn't tell.
+	WaitOnRateLimit bool
+	RateLimitWait   time.Duration // default: DefaultRateLimitWait
+}
+
+// Pager walks the pages of a list method by following the cursors,
+// until the results are exhausted or one of the limits is reached
+type Pager[T any] struct {
+	fetch    PageFunc[T]
+	query    *Query // query of the next page, nil once done
+	opts     PagerOptions
+	numItems int
+	numPages int
+}
+
+func NewPager[T any](fetch PageFunc[T], query Query, opts PagerOptions) *Pager[T] {
+	if opts.RateLimitWait <= 0 {
+		opts.RateLimitWait = DefaultRateLimitWait
+	}
+	return &Pager[T]{fetch: fetch, query: &query, opts: opts}
+}
+
+// NewPostPager returns a Pager over Search, GetFeed or GetPosts
+func NewPostPager(fetch func(query Query) (social.Posts, *Cursor, error), query Query, opts PagerOptions) *Pager[*social.Post] {
+	return NewPager(func(query Query) ([]*social.Post, *Cursor, error) {
+		return fetch(query)
+	}, query, opts)
+}
+
+// NewUserPager returns a Pager over GetFriends or GetFollowers
+func NewUserPager(fetch func(query Query) ([]*social.User, *Cursor, error), query Query, opts PagerOptions) *Pager[*social.User] {
+	return NewPager(PageFunc[*social.User](fetch), query, opts)
+}
+
+// Done reports whether there are no more pages to fetch
+func (p *Pager[T]) Done() bool {
+	if p.query == nil {
+		return true
+	}
+	if p.opts.MaxItems > 0 && p.numItems >= p.opts.MaxItems {
+		return true
+	}
+	if p.opts.MaxPages > 0 && p.numPages >= p.opts.MaxPages {
+		return true
+	}
+	return false
+}
+
+// Next fetches the next page of results. Pages may be empty before the
+// end, ie. when the provider filters the results out, see Done. It
+// returns an empty page and no error once the pager is done.
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if p.Done() {
+		return nil, nil
+	}
+
+	var items []T
+	var cursor *Cursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		items, cursor, err = p.fetch(*p.query)
+		if IsRateLimit(err) && p.opts.WaitOnRateLimit {
+			if err := sleepCtx(ctx, RetryAfter(err, p.opts.RateLimitWait)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	p.numPages++
+
+	if p.opts.MaxItems > 0 && p.numItems+len(items) > p.opts.MaxItems {
+		items = items[:p.opts.MaxItems-p.numItems]
+	}
+	p.numItems += len(items)
+
+	// Stop on cursors which are exhausted or don't move forward, but not
+	// on empty pages, as filtered pages may be empty with more to come
+	untilID := p.query.UntilID
+	p.query = nil
+	if cursor.HasNext() && cursor.Next.UntilID != untilID {
+		p.query = cursor.Next
+	}
+
+	return items, nil
+}
+
+// All iterates over the results of all the pages. Iteration stops after
+// yielding an error.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for !p.Done() {
+			items, err := p.Next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
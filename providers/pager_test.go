@@ -0,0 +1,126 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// scriptedPage is a page of a fake list method, next being the UntilID of
+// its next cursor, "" when exhausted
+type scriptedPage struct {
+	items []string
+	next  string
+}
+
+func scriptedFetch(pages map[string]scriptedPage, calls *int) PageFunc[string] {
+	return func(query Query) ([]string, *Cursor, error) {
+		*calls++
+		p := pages[query.UntilID]
+		return p.items, NewCursor(query, "", p.next), nil
+	}
+}
+
+func collect(t *testing.T, p *Pager[string]) []string {
+	t.Helper()
+	var got []string
+	for item, err := range p.All(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, item)
+	}
+	return got
+}
+
+func TestPagerEmptyPages(t *testing.T) {
+	// Filtered pages may be empty, with more results after them
+	pages := map[string]scriptedPage{
+		"":  {items: []string{"a", "b"}, next: "1"},
+		"1": {next: "2"},
+		"2": {next: "3"},
+		"3": {items: []string{"c"}},
+	}
+	calls := 0
+	got := collect(t, NewPager(scriptedFetch(pages, &calls), Query{}, PagerOptions{}))
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v, got %v", want, got)
+	}
+	if calls != 4 {
+		t.Errorf("expecting 4 calls, got %d", calls)
+	}
+}
+
+func TestPagerStalledCursor(t *testing.T) {
+	pages := map[string]scriptedPage{
+		"":  {items: []string{"a"}, next: "1"},
+		"1": {items: []string{"b"}, next: "1"},
+	}
+	calls := 0
+	got := collect(t, NewPager(scriptedFetch(pages, &calls), Query{}, PagerOptions{}))
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v, got %v", want, got)
+	}
+	if calls != 2 {
+		t.Errorf("expecting 2 calls, got %d", calls)
+	}
+}
+
+func TestPagerLimits(t *testing.T) {
+	pages := map[string]scriptedPage{
+		"":  {items: []string{"a", "b"}, next: "1"},
+		"1": {next: "2"},
+		"2": {items: []string{"c", "d"}, next: "3"},
+		"3": {items: []string{"e"}},
+	}
+
+	calls := 0
+	got := collect(t, NewPager(scriptedFetch(pages, &calls), Query{}, PagerOptions{MaxItems: 3}))
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MaxItems: expecting %v, got %v", want, got)
+	}
+
+	calls = 0
+	got = collect(t, NewPager(scriptedFetch(pages, &calls), Query{}, PagerOptions{MaxPages: 2}))
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) || calls != 2 {
+		t.Errorf("MaxPages: expecting %v in 2 calls, got %v in %d", want, got, calls)
+	}
+}
+
+func TestPagerRateLimit(t *testing.T) {
+	limited := true
+	fetch := func(query Query) ([]string, *Cursor, error) {
+		if limited {
+			limited = false
+			return nil, nil, ErrHitRateLimit.RetryAt(time.Now().Add(10 * time.Millisecond))
+		}
+		return []string{"a"}, nil, nil
+	}
+
+	p := NewPager(fetch, Query{}, PagerOptions{})
+	if _, err := p.Next(context.Background()); !IsRateLimit(err) {
+		t.Errorf("expecting ErrHitRateLimit, got %v", err)
+	}
+
+	limited = true
+	p = NewPager(fetch, Query{}, PagerOptions{WaitOnRateLimit: true})
+	items, err := p.Next(context.Background())
+	if err != nil || len(items) != 1 || !p.Done() {
+		t.Errorf("expecting the page after waiting, got %v, %v", items, err)
+	}
+}
+
+func TestPagerContext(t *testing.T) {
+	fetch := func(query Query) ([]string, *Cursor, error) {
+		return nil, nil, ErrHitRateLimit.RetryAt(time.Now().Add(time.Hour))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	p := NewPager(fetch, Query{}, PagerOptions{WaitOnRateLimit: true})
+	if _, err := p.Next(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expecting the context error, got %v", err)
+	}
+}
@@ -10,14 +10,92 @@ type Provider struct {
 	Configure func(appID string, appSecret string, oauthCallback string)
 	New       func(ctx context.Context, creds social.Credentials) (ProviderSession, error)
 	NewOAuth  func() social.OAuth
+
+	// Capabilities of the provider sessions
+	Capabilities Capabilities
+
+	// Limits of the posts published, see PublishThread
+	Limits PostLimits
+
+	// SessionLimits returns the post limits of a session, when they depend
+	// on its account or instance rather than on the provider, ie. the
+	// characters limit of a Mastodon instance. Nil when Limits apply to
+	// all the sessions, see LimitsOfSession.
+	SessionLimits func(ctx context.Context, s ProviderSession) (PostLimits, error)
+
+	// EntityURLs of the provider's profiles and tags
+	EntityURLs EntityURLs
+}
+
+// EntityURLs return the urls of the mentions and tags of a provider posts,
+// the funcs are nil when the provider has no such page
+type EntityURLs struct {
+	Profile func(username string) string
+	Hashtag func(tag string) string
+	Cashtag func(symbol string) string
+}
+
+// Capabilities are the session operations a provider supports, the others
+// return ErrUnsupported or ErrNotImplemented
+type Capabilities struct {
+	Search    bool `json:"search"`
+	Feed      bool `json:"feed"`
+	Posts     bool `json:"posts"`
+	Friends   bool `json:"friends"`
+	Followers bool `json:"followers"`
+	Publish   bool `json:"publish"`
+	Edit      bool `json:"edit"`
+	Delete    bool `json:"delete"`
+	Like      bool `json:"like"`    // Like and Unlike
+	Share     bool `json:"share"`   // retweet or reshare
+	Follow    bool `json:"follow"`  // Follow and Unfollow
+	Replies   bool `json:"replies"` // GetReplies and GetThread
+}
+
+// CapabilitiesOf returns the capabilities of a registered provider
+func CapabilitiesOf(providerID string) (Capabilities, error) {
+	r, ok := Registry[providerID]
+	if !ok {
+		return Capabilities{}, ErrUnknownProviderID
+	}
+	return r.Capabilities, nil
 }
 
 type ProviderSession interface {
 	// ID of the Provider
 	ID() string
 
-	// Post a message to the provider and return the new Post object created.
-	Post(ctx context.Context, msg string, link string) (*social.Post, error)
+	// Credentials of the session
+	Credentials() social.Credentials
+
+	// Publish a post to the provider and return the new Post object
+	// created. The media files are uploaded first, and ErrInvalidAsset is
+	// returned when the provider doesn't accept their combination.
+	Publish(ctx context.Context, req *PostRequest) (*social.Post, error)
+
+	// Delete a published post, or ErrPostNotFound when it doesn't exist
+	DeletePost(ctx context.Context, id string) error
+
+	// Edit a published post, and return the updated Post object. It returns
+	// ErrUnsupported when the provider doesn't allow edits, or editing
+	// some of the request fields.
+	EditPost(ctx context.Context, id string, req *PostRequest) (*social.Post, error)
+
+	// Like a post
+	Like(ctx context.Context, postID string) error
+
+	// Unlike a post liked before
+	Unlike(ctx context.Context, postID string) error
+
+	// Share a post with the user's followers, ie. retweet it, and return
+	// the new Post object created
+	Share(ctx context.Context, postID string) (*social.Post, error)
+
+	// Follow a user
+	Follow(ctx context.Context, userID string) error
+
+	// Unfollow a user followed before
+	Unfollow(ctx context.Context, userID string) error
 
 	// Search content on a provider network
 	Search(query Query) (social.Posts, *Cursor, error)
@@ -36,14 +114,41 @@ type ProviderSession interface {
 
 	// Get a user's followers list
 	GetFollowers(query Query) ([]*social.User, *Cursor, error)
+
+	// Get the replies to a post, newest first
+	GetReplies(ctx context.Context, postID string, query Query) (social.Posts, *Cursor, error)
+
+	// Get the conversation of a post, as its root post with the replies
+	// nested in Replies, see BuildThread. The cursor pages the replies.
+	GetThread(ctx context.Context, postID string, query Query) (*social.Post, *Cursor, error)
 }
 
-func NewSession(ctx context.Context, providerID string, creds social.Credentials) (ProviderSession, error) {
+// NewSession returns a session of the provider for the credentials, wrapped
+// with the middlewares of the options, the ones registered with Use and
+// the DefaultMiddleware, from the outermost to the innermost. Its cursor
+// tokens are bound to the provider.
+func NewSession(ctx context.Context, providerID string, creds social.Credentials, opts ...SessionOption) (ProviderSession, error) {
 	r, ok := Registry[providerID]
 	if !ok {
 		return nil, ErrUnknownProviderID
 	}
-	return r.New(ctx, creds)
+	s, err := r.New(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &sessionOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var mws []Middleware
+	mws = append(mws, o.middlewares...)
+	mws = append(mws, middlewares...)
+	if !o.noDefaults {
+		mws = append(mws, DefaultMiddleware()...)
+	}
+	return bindCursors(Chain(mws...)(s)), nil
 }
 
 var Registry = make(map[string]*Provider)
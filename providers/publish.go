@@ -0,0 +1,59 @@
+package providers
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// PostRequest is a post to publish with ProviderSession.Publish. The
+// providers reject the fields they don't support with ErrUnsupported,
+// instead of publishing a different post than the one requested.
+type PostRequest struct {
+	Message string
+
+	// Link shared with the post, appended to the message by the providers
+	// without link previews
+	Link string
+
+	// LinkPreview overrides the preview of the Link
+	LinkPreview *LinkPreview
+
+	Media []*MediaUpload
+
+	// ReplyTo is the id of the post replied to
+	ReplyTo string
+
+	// QuoteURL is the url of the post quoted
+	QuoteURL string
+
+	// Visibility of the post, the provider default when empty
+	Visibility Visibility
+
+	// ScheduledAt publishes the post later
+	ScheduledAt *time.Time
+
+	// Options are provider specific args, passed as-is to the provider api
+	Options url.Values
+}
+
+// LinkPreview is the preview of a shared link
+type LinkPreview struct {
+	Title       string
+	Description string
+	ImageURL    string
+}
+
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityFriends Visibility = "friends" // friends or followers
+	VisibilityPrivate Visibility = "private" // only the user
+)
+
+// ErrUnsupportedField returns the ErrUnsupported error of a PostRequest
+// field the provider doesn't support
+func ErrUnsupportedField(providerID string, field string) error {
+	return ErrUnsupported.Err(fmt.Errorf("%s doesn't support the post %s", providerID, field))
+}
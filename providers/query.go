@@ -1,9 +1,13 @@
 package providers
 
 import (
+	"fmt"
 	"net/url"
-	"strconv"
+	"reflect"
 	"strings"
+	"time"
+
+	querystring "github.com/google/go-querystring/query"
 )
 
 const (
@@ -15,101 +19,165 @@ var (
 	NoQuery = Query{}
 )
 
-// TODO: use https://github.com/google/go-querystring with
-// `url` struct tags to easier parse/build query strings
+// Query is encoded to and decoded from url args by its `url` struct tags,
+// see ToURLArgs and ParseQuery
 type Query struct {
-	Search   SearchParts
-	Filter   string // Second-pass keywords filter
-	Username string // Query by a specific username
-	UserID   string
+	Search   SearchParts `url:"q,omitempty"`
+	Filter   string      `url:"filter,omitempty"`   // Second-pass keywords filter
+	Username string      `url:"username,omitempty"` // Query by a specific username
+	UserID   string      `url:"userid,omitempty"`
+
+	Limit   int    `url:"limit"`
+	Sort    string `url:"sort,omitempty"`     // recent,popular (default: recent)
+	SinceID string `url:"since_id,omitempty"` // TODO: rename these to NextID and PrevID?
+	UntilID string `url:"until_id,omitempty"`
+	Perm    string `url:"perm,omitempty"` // read or write, default: read
 
-	Limit   int
-	Sort    string // recent,popular (default: recent)
-	SinceID string // TODO: rename these to NextID and PrevID?
-	UntilID string
-	Perm    string // read or write, default: read
+	Since time.Time `url:"since,omitempty" layout:"2006-01-02T15:04:05.999999999Z07:00"` // Posts published at or after, optional
+	Until time.Time `url:"until,omitempty" layout:"2006-01-02T15:04:05.999999999Z07:00"` // Posts published before, optional
+	Lang  string    `url:"lang,omitempty"`                                               // Posts language, ISO 639-1 code, optional
 
-	Params url.Values
+	// Params are all the args the query was parsed from, including the
+	// provider-specific ones
+	Params url.Values `url:"-"`
 }
 
+// queryKeys are the url args names of the Query fields
+var queryKeys = valuesKeys(reflect.TypeOf(Query{}))
+
 func NewQuery(args url.Values) Query {
+	q, _ := ParseQuery(args)
+	return q
+}
+
+// ParseQuery is like NewQuery, but reports an invalid `cursor` argument
+// instead of silently starting from the first page, and invalid args or
+// search query syntax errors instead of falling back to the defaults
+func ParseQuery(args url.Values) (Query, error) {
 	q := Query{
 		Limit: DefaultNumResults,
 		Sort:  "recent",
 	}
 
 	if args == nil {
-		return q
+		return q, nil
 	}
 
-	q.Search = NewSearchParts(args.Get("q"))
-	q.Filter = args.Get("filter")
-	q.Username = args.Get("username")
-	q.UserID = args.Get("userid")
+	// An opaque cursor token takes over the args it was encoded from
+	var cursorErr error
+	if token := args.Get("cursor"); token != "" {
+		var cargs url.Values
+		cargs, cursorErr = DecodeCursor(token)
 
-	if q.Username != "" {
-		q.Search.Usernames = append(q.Search.Usernames, q.Username)
+		merged := url.Values{}
+		for k, v := range args {
+			merged[k] = v
+		}
+		if cursorErr == nil {
+			merged.Del("since_id")
+			merged.Del("until_id")
+		}
+		for k, v := range cargs {
+			merged[k] = v
+		}
+		args = merged
 	}
 
-	limit, err := strconv.Atoi(args.Get("limit"))
-	if err != nil || limit < 1 || limit > MaxNumResults {
+	err := decodeValues(args, &q)
+
+	if q.Username != "" {
+		q.Search.AddUsername(q.Username)
+	}
+	if q.Limit < 1 || q.Limit > MaxNumResults {
 		q.Limit = DefaultNumResults
-	} else {
-		q.Limit = limit
 	}
-
-	sort := args.Get("sort")
-	if sort == "popular" {
-		q.Sort = sort
+	if q.Sort != "popular" {
+		q.Sort = "recent"
 	}
-
-	q.SinceID = args.Get("since_id")
-	q.UntilID = args.Get("until_id")
-	q.Perm = args.Get("perm")
+	q.Lang = strings.ToLower(q.Lang)
 
 	q.Params = args
 
-	return q
+	if cursorErr != nil {
+		return q, cursorErr
+	}
+	return q, err
 }
 
+// ToURLArgs encodes the query fields over a copy of its Params, so the
+// args are parsed back by ParseQuery into the same query. Params of the
+// query fields which are unset are dropped.
 func (q Query) ToURLArgs() url.Values {
-	args := q.Params
-	if q.Search.String() != "" {
-		args.Set("q", q.Search.String())
-	}
-	if q.Filter != "" {
-		args.Set("filter", q.Filter)
-	}
-	if q.Username != "" {
-		args.Set("username", q.Username)
-	}
-	if q.UserID != "" {
-		args.Set("userid", q.UserID)
+	args := cloneParams(q.Params)
+	for _, k := range queryKeys {
+		args.Del(k)
 	}
-	args.Set("limit", strconv.Itoa(q.Limit))
 
-	if q.Sort != "recent" {
-		args.Set("sort", q.Sort)
-	}
+	q.Since, q.Until = q.Since.UTC(), q.Until.UTC()
 
-	if q.SinceID != "" {
-		args.Set("since_id", q.SinceID)
+	// Only the SearchParts encoder could fail, and it never does
+	vals, _ := querystring.Values(q)
+	for k, v := range vals {
+		args[k] = v
 	}
-	if q.UntilID != "" {
-		args.Set("until_id", q.UntilID)
+	return args
+}
+
+// parseQueryTime parses a since/until query arg, either a RFC 3339 time
+// or a YYYY-MM-DD date in UTC
+func parseQueryTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
 	}
-	if q.Perm != "" {
-		args.Set("perm", q.Perm)
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
 	}
-	return args
+	return time.Time{}, ErrInvalidQuery.Err(fmt.Errorf("invalid date %q, expecting RFC 3339 or YYYY-MM-DD", s))
 }
 
 // Search query parts
 type SearchParts struct {
 	Usernames, Tags, Words []string
+
+	// Expr is the parsed search query, see ParseSearch. The Usernames, Tags
+	// and Words are the plain terms of Expr which must all match.
+	Expr SearchNode
 }
 
 func NewSearchParts(q string) SearchParts {
+	qp, _ := ParseSearchParts(q)
+	return qp
+}
+
+// ParseSearchParts parses a search query, see ParseSearch. On syntax errors
+// the query is split into plain keywords, and the error is returned.
+func ParseSearchParts(q string) (SearchParts, error) {
+	expr, err := ParseSearch(q)
+	if err != nil {
+		return splitSearchParts(q), err
+	}
+
+	qp := SearchParts{Expr: expr}
+	for _, node := range andNodes(expr) {
+		term, ok := node.(*SearchTerm)
+		if !ok {
+			continue
+		}
+		switch term.Kind {
+		case SearchUser:
+			qp.Usernames = append(qp.Usernames, term.Value)
+		case SearchTag:
+			qp.Tags = append(qp.Tags, term.Value)
+		default:
+			qp.Words = append(qp.Words, term.String())
+		}
+	}
+	return qp, nil
+}
+
+func splitSearchParts(q string) SearchParts {
 	qs := strings.TrimSpace(q)
 	qp := SearchParts{}
 	parts := strings.Split(qs, " ")
@@ -130,7 +198,48 @@ func NewSearchParts(q string) SearchParts {
 	return qp
 }
 
+// EncodeValues implements go-querystring's query.Encoder
+func (sq SearchParts) EncodeValues(key string, v *url.Values) error {
+	if s := sq.String(); s != "" {
+		v.Set(key, s)
+	}
+	return nil
+}
+
+// DecodeValues parses the search query of the key arg, see ParseSearchParts
+func (sq *SearchParts) DecodeValues(key string, v url.Values) error {
+	var err error
+	*sq, err = ParseSearchParts(v.Get(key))
+	return err
+}
+
+// AddUsername adds a @username term which must match
+func (sq *SearchParts) AddUsername(username string) {
+	for _, u := range sq.Usernames {
+		if u == username {
+			return
+		}
+	}
+	sq.Usernames = append(sq.Usernames, username)
+
+	if sq.Expr != nil {
+		nodes := []SearchNode{&SearchTerm{Kind: SearchUser, Value: username}}
+		sq.Expr = &SearchAnd{Nodes: append(nodes, andNodes(sq.Expr)...)}
+	}
+}
+
+// OnlyUsernames reports whether the search query is made of @usernames only
+func (sq SearchParts) OnlyUsernames() bool {
+	if len(sq.Usernames) == 0 || len(sq.Tags) > 0 || len(sq.Words) > 0 {
+		return false
+	}
+	return len(andNodes(sq.Expr)) <= len(sq.Usernames)
+}
+
 func (sq SearchParts) String() (s string) {
+	if sq.Expr != nil {
+		return sq.Expr.String()
+	}
 	if len(sq.Usernames) > 0 {
 		s += sq.buildPart(sq.Usernames, "@") + " "
 	}
@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"math/rand"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// randQuery is a random valid query, as returned by ParseQuery
+type randQuery struct {
+	Query
+}
+
+func (randQuery) Generate(r *rand.Rand, size int) reflect.Value {
+	word := func() string {
+		const letters = "abcdefghijklmnopqrstuvwxyz0123456789_"
+		b := make([]byte, 1+r.Intn(8))
+		for i := range b {
+			b[i] = letters[r.Intn(len(letters))]
+		}
+		return string(b)
+	}
+	maybe := func(s string) string {
+		if r.Intn(2) == 0 {
+			return ""
+		}
+		return s
+	}
+	date := func() time.Time {
+		if r.Intn(2) == 0 {
+			return time.Time{}
+		}
+		return time.Unix(r.Int63n(4e9), r.Int63n(1e9)).UTC()
+	}
+
+	q := Query{
+		Filter:  maybe(word() + " -" + word()),
+		UserID:  maybe(word()),
+		Limit:   1 + r.Intn(MaxNumResults),
+		Sort:    []string{"recent", "popular"}[r.Intn(2)],
+		SinceID: maybe(word()),
+		UntilID: maybe(word()),
+		Perm:    maybe("write"),
+		Since:   date(),
+		Until:   date(),
+		Lang:    maybe("en"),
+	}
+
+	var terms []string
+	for i := r.Intn(size%5 + 1); i > 0; i-- {
+		terms = append(terms, []string{"", "@", "#", "-"}[r.Intn(4)]+word())
+	}
+	if r.Intn(3) == 0 {
+		terms = append(terms, "("+word()+" OR #"+word()+")")
+	}
+	if r.Intn(3) == 0 {
+		q.Username = word()
+		terms = append(terms, "@"+q.Username)
+	}
+	q.Search = NewSearchParts(strings.Join(terms, " "))
+
+	if r.Intn(2) == 0 {
+		q.Params = url.Values{"x_" + word(): {word()}}
+	}
+
+	return reflect.ValueOf(randQuery{q})
+}
+
+func TestQueryRoundTrip(t *testing.T) {
+	f := func(rq randQuery) bool {
+		q := rq.Query
+		args := q.ToURLArgs()
+
+		got, err := ParseQuery(args)
+		if err != nil {
+			t.Logf("%s: %v", args.Encode(), err)
+			return false
+		}
+		if !reflect.DeepEqual(got.Params, args) {
+			t.Logf("params %v, want %v", got.Params, args)
+			return false
+		}
+
+		got.Params, q.Params = nil, nil
+		if !reflect.DeepEqual(got, q) {
+			t.Logf("%s:\n got %#v\nwant %#v", args.Encode(), got, q)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQueryURLArgsRoundTrip(t *testing.T) {
+	f := func(rq randQuery) bool {
+		args := rq.ToURLArgs()
+		q, err := ParseQuery(args)
+		if err != nil {
+			return false
+		}
+		return reflect.DeepEqual(q.ToURLArgs(), args)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQueryURLArgsCopiesParams(t *testing.T) {
+	f := func(rq randQuery) bool {
+		q := rq.Query
+		q.Params = url.Values{"until_id": {"stale"}, "x": {"1"}}
+		q.UntilID = ""
+
+		args := q.ToURLArgs()
+		args.Set("x", "2")
+
+		return q.Params.Get("until_id") == "stale" && q.Params.Get("x") == "1" &&
+			args.Get("until_id") == ""
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQueryURLArgsNilParams(t *testing.T) {
+	q := Query{Limit: 10, UntilID: "1"}
+	args := q.ToURLArgs()
+	if args.Get("limit") != "10" || args.Get("until_id") != "1" {
+		t.Errorf("unexpected args %v", args)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	f := func(rq randQuery) bool {
+		q := rq.Query
+		q.Params = nil
+
+		got, err := ParseQuery(url.Values{"cursor": {encodeCursor(t, q)}})
+		if err != nil {
+			return false
+		}
+		got.Params = nil
+		return reflect.DeepEqual(got, q)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
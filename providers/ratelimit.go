@@ -0,0 +1,234 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// RateLimits tracks the rate limits reported by the providers for all the
+// sessions, see RateLimiter
+var RateLimits = &RateLimiter{}
+
+// RateLimit is the state of a provider rate limit window
+type RateLimit struct {
+	Limit     int       // calls allowed in the window
+	Remaining int       // calls left in the window
+	ResetAt   time.Time // when the window resets, zero when unknown
+}
+
+// Exhausted reports whether there are no more than reserve calls left in
+// the window, at the time now
+func (rl RateLimit) Exhausted(reserve int, now time.Time) bool {
+	return rl.Remaining <= reserve && rl.ResetAt.After(now)
+}
+
+// RateLimiter tracks the rate limits reported by providers, per credential
+// and endpoint, to hold back calls which would hit a rate limit instead of
+// having the provider lock the account
+type RateLimiter struct {
+	// Delay holds the calls until the rate limit window resets, instead
+	// of failing them with ErrHitRateLimit right away
+	Delay bool
+
+	// MaxWait is the longest a call is delayed, calls which would have to
+	// wait longer fail with ErrHitRateLimit. Default: DefaultRateLimitWait
+	MaxWait time.Duration
+
+	// Reserve is the number of calls kept unused in each window, ie. for
+	// the interactive requests of a user while their batch jobs run
+	Reserve int
+
+	mu        sync.Mutex
+	limits    map[string]RateLimit // by credential and endpoint key
+	lastPrune time.Time
+}
+
+// rateLimitPruneInterval is how often the windows which were reset are
+// removed from a RateLimiter
+const rateLimitPruneInterval = time.Minute
+
+// rateLimitKey returns the key of a credential endpoint, with a hash of
+// the credential so the tokens aren't kept around
+func rateLimitKey(credential, endpoint string) string {
+	h := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(h[:16]) + " " + endpoint
+}
+
+// idSegment matches the numeric ids of an url path, ie. the status id of
+// "/1.1/statuses/retweet/123.json"
+var idSegment = regexp.MustCompile(`/\d+(\.[a-z]+)?(/|$)`)
+
+// EndpointTemplate returns the route of an url path, with its numeric ids
+// replaced by ":id", as providers rate limit the routes, not each url
+func EndpointTemplate(path string) string {
+	// Consecutive ids share a slash, so it's applied until none is left
+	for idSegment.MatchString(path) {
+		path = idSegment.ReplaceAllString(path, "/:id$1$2")
+	}
+	return path
+}
+
+// Get returns the last rate limit reported for the credential endpoint
+func (l *RateLimiter) Get(credential, endpoint string) (RateLimit, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rl, ok := l.limits[rateLimitKey(credential, endpoint)]
+	return rl, ok
+}
+
+// Update records the rate limit reported by the provider for the
+// credential endpoint
+func (l *RateLimiter) Update(credential, endpoint string, rl RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limits == nil {
+		l.limits = map[string]RateLimit{}
+	}
+	l.limits[rateLimitKey(credential, endpoint)] = rl
+
+	if now := time.Now(); now.Sub(l.lastPrune) >= rateLimitPruneInterval {
+		l.prune(now)
+	}
+}
+
+// prune removes the windows which were reset, the next responses of their
+// endpoints tell the new ones
+func (l *RateLimiter) prune(now time.Time) {
+	for key, rl := range l.limits {
+		if !rl.ResetAt.After(now) {
+			delete(l.limits, key)
+		}
+	}
+	l.lastPrune = now
+}
+
+// Allow takes a call from the credential endpoint window, or returns
+// ErrHitRateLimit with the window reset time when it's exhausted
+func (l *RateLimiter) Allow(credential, endpoint string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := rateLimitKey(credential, endpoint)
+	rl, ok := l.limits[key]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	if rl.Exhausted(l.Reserve, now) {
+		return ErrHitRateLimit.RetryAt(rl.ResetAt)
+	}
+	if !rl.ResetAt.After(now) {
+		// The window was reset, wait for the next response to tell
+		delete(l.limits, key)
+		return nil
+	}
+	// Count the call right away, so concurrent calls don't overshoot
+	rl.Remaining--
+	l.limits[key] = rl
+	return nil
+}
+
+// Wait takes a call from the credential endpoint window like Allow, but
+// waits for the window to reset when Delay is set and it resets within
+// MaxWait
+func (l *RateLimiter) Wait(ctx context.Context, credential, endpoint string) error {
+	maxWait := l.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultRateLimitWait
+	}
+	for {
+		err := l.Allow(credential, endpoint)
+		if err == nil || !l.Delay {
+			return err
+		}
+		d := RetryAfter(err, maxWait)
+		if d > maxWait {
+			return err
+		}
+		// Give the provider a moment past the reset
+		if err := sleepCtx(ctx, d+time.Second); err != nil {
+			return err
+		}
+	}
+}
+
+// RateLimitError returns err with the reset time of the credential
+// endpoint window when it's a rate limit error which doesn't tell
+func (l *RateLimiter) RateLimitError(credential, endpoint string, err error) error {
+	if !IsRateLimit(err) || RetryAfter(err, 0) > 0 {
+		return err
+	}
+	rl, ok := l.Get(credential, endpoint)
+	if !ok {
+		return err
+	}
+	var e *Error
+	errors.As(err, &e)
+	return e.RetryAt(rl.ResetAt)
+}
+
+// RateLimitTransport is a http.RoundTripper which records the rate limits
+// of a credential from the provider responses, and holds back the requests
+// to exhausted endpoints, see RateLimiter
+type RateLimitTransport struct {
+	Limiter    *RateLimiter // default: RateLimits
+	Credential string
+
+	// Endpoint returns the rate limited endpoint of a request, default:
+	// the EndpointTemplate of the request url path
+	Endpoint func(req *http.Request) string
+
+	// Parse returns the rate limit reported in a provider response
+	Parse func(resp *http.Response) (RateLimit, bool)
+
+	Base http.RoundTripper // default: http.DefaultTransport
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := t.limiter()
+	endpoint := t.endpoint(req)
+
+	if err := limiter.Wait(req.Context(), t.Credential, endpoint); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if rl, ok := t.Parse(resp); ok {
+		limiter.Update(t.Credential, endpoint, rl)
+	}
+	return resp, nil
+}
+
+// RateLimitError is like RateLimiter.RateLimitError, for the endpoint of
+// the transport credential
+func (t *RateLimitTransport) RateLimitError(endpoint string, err error) error {
+	return t.limiter().RateLimitError(t.Credential, endpoint, err)
+}
+
+func (t *RateLimitTransport) limiter() *RateLimiter {
+	if t.Limiter == nil {
+		return RateLimits
+	}
+	return t.Limiter
+}
+
+func (t *RateLimitTransport) endpoint(req *http.Request) string {
+	if t.Endpoint == nil {
+		return EndpointTemplate(req.URL.Path)
+	}
+	return t.Endpoint(req)
+}
@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointTemplate(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/1.1/statuses/home_timeline.json", "/1.1/statuses/home_timeline.json"},
+		{"/1.1/statuses/retweet/123.json", "/1.1/statuses/retweet/:id.json"},
+		{"/1.1/statuses/123/retweets", "/1.1/statuses/:id/retweets"},
+		{"/v2/users/1/following/2", "/v2/users/:id/following/:id"},
+		{"/v2/users/1/2", "/v2/users/:id/:id"},
+	}
+	for _, tt := range tests {
+		if got := EndpointTemplate(tt.path); got != tt.want {
+			t.Errorf("%q: expecting %q, got %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	l := &RateLimiter{}
+	l.Update("secret-token", "/search", RateLimit{Limit: 10, Remaining: 0, ResetAt: time.Now().Add(time.Hour)})
+
+	for key := range l.limits {
+		if strings.Contains(key, "secret-token") {
+			t.Errorf("expecting the credential to be hashed, got key %q", key)
+		}
+	}
+	if err := l.Allow("secret-token", "/search"); !errors.Is(err, ErrHitRateLimit) {
+		t.Errorf("expecting ErrHitRateLimit, got %v", err)
+	}
+	if err := l.Allow("other-token", "/search"); err != nil {
+		t.Errorf("expecting other credentials to be allowed, got %v", err)
+	}
+}
+
+func TestRateLimiterPrune(t *testing.T) {
+	now := time.Now()
+	l := &RateLimiter{lastPrune: now}
+	l.Update("a", "/expired", RateLimit{Limit: 10, ResetAt: now.Add(-time.Minute)})
+	l.Update("a", "/unknown", RateLimit{Limit: 10, Remaining: 5})
+	l.Update("a", "/current", RateLimit{Limit: 10, ResetAt: now.Add(time.Hour)})
+
+	// Pruned at most once per interval
+	if len(l.limits) != 3 {
+		t.Fatalf("expecting 3 windows before the prune interval, got %d", len(l.limits))
+	}
+	l.lastPrune = now.Add(-rateLimitPruneInterval)
+	l.Update("a", "/current", RateLimit{Limit: 10, ResetAt: now.Add(time.Hour)})
+	if _, ok := l.Get("a", "/current"); !ok || len(l.limits) != 1 {
+		t.Errorf("expecting only the current window to be kept, got %v", l.limits)
+	}
+}
@@ -0,0 +1,73 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/go-social/social"
+)
+
+// HTML renders the post contents as a paragraph of escaped HTML, with the
+// mentions, tags and links as anchors, followed by the media as images and
+// videos
+func HTML(post *social.Post) string {
+	urls := entityURLs(post)
+
+	var b strings.Builder
+	b.WriteString("<p>")
+	for _, s := range segments(post) {
+		if s.entity == nil {
+			b.WriteString(htmlText(s.text))
+			continue
+		}
+		href, text := link(urls, s)
+		if href == "" {
+			b.WriteString(htmlText(s.text))
+			continue
+		}
+		fmt.Fprintf(&b, `<a href="%s" class="%s" rel="nofollow noopener noreferrer">%s</a>`,
+			html.EscapeString(href), s.entity.Type, htmlText(text))
+	}
+	b.WriteString("</p>")
+
+	for _, m := range post.Media {
+		b.WriteString(htmlMedia(m))
+	}
+	return b.String()
+}
+
+// htmlText escapes text, with its line breaks as <br>
+func htmlText(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+func htmlMedia(m *social.Media) string {
+	src := safeURL(m.URL)
+	if src == "" {
+		return ""
+	}
+
+	var attrs strings.Builder
+	fmt.Fprintf(&attrs, ` src="%s"`, html.EscapeString(src))
+	if m.Width > 0 && m.Height > 0 {
+		fmt.Fprintf(&attrs, ` width="%d" height="%d"`, m.Width, m.Height)
+	}
+
+	switch m.Type {
+	case social.MediaImage:
+		return fmt.Sprintf(`<img%s alt="%s">`, attrs.String(), html.EscapeString(m.AltText))
+	case social.MediaVideo, social.MediaGIF:
+		if poster := safeURL(m.ThumbnailURL); poster != "" {
+			fmt.Fprintf(&attrs, ` poster="%s"`, html.EscapeString(poster))
+		}
+		if m.AltText != "" {
+			fmt.Fprintf(&attrs, ` aria-label="%s"`, html.EscapeString(m.AltText))
+		}
+		if m.Type == social.MediaGIF {
+			return fmt.Sprintf(`<video%s autoplay loop muted playsinline></video>`, attrs.String())
+		}
+		return fmt.Sprintf(`<video%s controls></video>`, attrs.String())
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-social/social"
+)
+
+// Markdown renders the post contents as escaped Markdown, with the
+// mentions, tags and links as links, followed by the media as images, or
+// as linked thumbnails for videos
+func Markdown(post *social.Post) string {
+	urls := entityURLs(post)
+
+	var b strings.Builder
+	for _, s := range segments(post) {
+		if s.entity == nil {
+			b.WriteString(markdownText(s.text))
+			continue
+		}
+		href, text := link(urls, s)
+		if href == "" {
+			b.WriteString(markdownText(s.text))
+			continue
+		}
+		b.WriteString("[" + markdownText(text) + "](" + markdownURL(href) + ")")
+	}
+	md := escapeLineStarts(b.String())
+
+	for _, m := range post.Media {
+		if mm := markdownMedia(m); mm != "" {
+			md += "\n\n" + mm
+		}
+	}
+	return md
+}
+
+// markdownEscaper escapes the characters starting Markdown and html markup,
+// and the html entities
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`(`, `\(`, `)`, `\)`, `#`, `\#`, `!`, `\!`, `<`, `\<`, `>`, `\>`,
+	`~`, `\~`, `|`, `\|`, `&`, `\&`,
+)
+
+// lineMarker matches the "-", "+" and "1." list markers, and the "=" and
+// "-" heading underlines, which start Markdown blocks at line starts only
+var lineMarker = regexp.MustCompile(`(?m)^[ \t]*(?:[-+=]|\d{1,9}\.)`)
+
+// escapeLineStarts escapes the markers starting the lines of the escaped
+// text
+func escapeLineStarts(md string) string {
+	return lineMarker.ReplaceAllStringFunc(md, func(m string) string {
+		return m[:len(m)-1] + `\` + m[len(m)-1:]
+	})
+}
+
+// markdownText escapes text, with its line breaks as hard breaks
+func markdownText(text string) string {
+	return strings.ReplaceAll(markdownEscaper.Replace(text), "\n", "  \n")
+}
+
+// markdownURL escapes the characters ending a Markdown link destination
+var markdownURL = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E").Replace
+
+func markdownMedia(m *social.Media) string {
+	src := safeURL(m.URL)
+	if src == "" {
+		return ""
+	}
+	alt := markdownText(m.AltText)
+
+	switch m.Type {
+	case social.MediaImage:
+		return "![" + alt + "](" + markdownURL(src) + ")"
+	case social.MediaVideo, social.MediaGIF:
+		if alt == "" {
+			alt = string(m.Type)
+		}
+		if thumb := safeURL(m.ThumbnailURL); thumb != "" {
+			return "[![" + alt + "](" + markdownURL(thumb) + ")](" + markdownURL(src) + ")"
+		}
+		return "[" + alt + "](" + markdownURL(src) + ")"
+	}
+	return ""
+}
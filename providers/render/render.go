@@ -0,0 +1,116 @@
+// Package render renders post contents as escaped HTML or Markdown, with
+// their mentions, tags and links linked to the provider pages, and their
+// media inline.
+package render
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+// segment is a part of a post contents, plain text or an entity
+type segment struct {
+	text   string
+	entity *social.Entity
+}
+
+// segments splits the post contents by its entities, extracted when the
+// post has none. Entities out of the contents or overlapping are ignored.
+func segments(post *social.Post) []segment {
+	entities := post.Entities
+	if entities == nil {
+		entities = providers.ExtractEntities(post.Contents)
+	}
+
+	text := []rune(post.Contents)
+	var segs []segment
+	last := 0
+	for i := range entities {
+		e := &entities[i]
+		if e.Start < last || e.End > len(text) || e.Start >= e.End {
+			continue
+		}
+		if e.Start > last {
+			segs = append(segs, segment{text: string(text[last:e.Start])})
+		}
+		segs = append(segs, segment{text: string(text[e.Start:e.End]), entity: e})
+		last = e.End
+	}
+	if last < len(text) {
+		segs = append(segs, segment{text: string(text[last:])})
+	}
+	return trimMediaLinks(segs)
+}
+
+// trimMediaLinks removes the links to the post media, rendered inline, and
+// the spaces left before them at the end of the contents
+func trimMediaLinks(segs []segment) []segment {
+	res := segs[:0]
+	for _, s := range segs {
+		if s.entity != nil && s.entity.Media {
+			continue
+		}
+		res = append(res, s)
+	}
+	for len(res) > 0 && res[len(res)-1].entity == nil && strings.TrimSpace(res[len(res)-1].text) == "" {
+		res = res[:len(res)-1]
+	}
+	if n := len(res); n > 0 && res[n-1].entity == nil {
+		res[n-1].text = strings.TrimRight(res[n-1].text, " \t\n")
+	}
+	return res
+}
+
+// link returns the url and the text of an entity link, or "" when the
+// entity is rendered as text
+func link(urls providers.EntityURLs, s segment) (href string, text string) {
+	e := s.entity
+	var u string
+	switch e.Type {
+	case social.EntityMention:
+		if urls.Profile != nil {
+			u = urls.Profile(e.Value)
+		}
+	case social.EntityHashtag:
+		if urls.Hashtag != nil {
+			u = urls.Hashtag(e.Value)
+		}
+	case social.EntityCashtag:
+		if urls.Cashtag != nil {
+			u = urls.Cashtag(e.Value)
+		}
+	case social.EntityURL:
+		u = e.Value
+		if e.ExpandedURL != "" {
+			u = e.ExpandedURL
+		}
+		if e.DisplayURL != "" {
+			return safeURL(u), e.DisplayURL
+		}
+	}
+	return safeURL(u), s.text
+}
+
+// safeURL returns the url when it's an http one, and "" otherwise, ie. for
+// javascript: urls. Links without a scheme, ie. "www.example.com", get
+// http added.
+func safeURL(rawURL string) string {
+	if strings.HasPrefix(strings.ToLower(rawURL), "www.") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return u.String()
+}
+
+func entityURLs(post *social.Post) providers.EntityURLs {
+	if p, ok := providers.Registry[post.Provider]; ok {
+		return p.EntityURLs
+	}
+	return providers.EntityURLs{}
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+func init() {
+	providers.Registry["render"] = &providers.Provider{
+		EntityURLs: providers.EntityURLs{
+			Profile: func(username string) string { return "https://example.com/" + username },
+			Hashtag: func(tag string) string { return "https://example.com/tags/" + tag },
+		},
+	}
+}
+
+func TestMarkdownEscaping(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     string
+	}{
+		{"*bold* _it_ `code` [x](y) <b> ~s~ a|b", `\*bold\* \_it\_ \` + "`code\\`" + ` \[x\]\(y\) \<b\> \~s\~ a\|b`},
+		{"Tom &amp; Jerry & co", `Tom \&amp; Jerry \& co`},
+		{"- item\n+ item\n1. item\n  2. item", "\\- item  \n\\+ item  \n1\\. item  \n  2\\. item"},
+		{"title\n===\n---", "title  \n\\===  \n\\---"},
+		{"a - b + c 1. d", "a - b + c 1. d"},
+		{"> quote\n# title", `\> quote  ` + "\n" + `\# title`},
+	}
+	for _, tt := range tests {
+		if got := Markdown(&social.Post{Contents: tt.contents}); got != tt.want {
+			t.Errorf("%q: expecting %q, got %q", tt.contents, tt.want, got)
+		}
+	}
+}
+
+func TestUnsafeURLs(t *testing.T) {
+	post := &social.Post{
+		Contents: "click me",
+		Entities: []social.Entity{{Type: social.EntityURL, Value: "me", ExpandedURL: "javascript:alert(1)", Start: 6, End: 8}},
+		Media: []*social.Media{
+			{Type: social.MediaImage, URL: "javascript:alert(1)"},
+			{Type: social.MediaVideo, URL: "https://example.com/v.mp4", ThumbnailURL: "data:image/png;base64,x"},
+		},
+	}
+
+	md := Markdown(post)
+	if strings.Contains(md, "javascript") || strings.Contains(md, "data:") {
+		t.Errorf("expecting the unsafe urls to be left out, got %q", md)
+	}
+	if want := "click me\n\n[video](https://example.com/v.mp4)"; md != want {
+		t.Errorf("expecting %q, got %q", want, md)
+	}
+
+	h := HTML(post)
+	if strings.Contains(h, "javascript") || strings.Contains(h, "data:") {
+		t.Errorf("expecting the unsafe urls to be left out, got %q", h)
+	}
+	if want := `<p>click me</p><video src="https://example.com/v.mp4" controls></video>`; h != want {
+		t.Errorf("expecting %q, got %q", want, h)
+	}
+}
+
+func TestEntityOffsets(t *testing.T) {
+	// Code point offsets, past the multi-byte characters
+	post := &social.Post{
+		Provider: "render",
+		Contents: "日本 @gopher #タグ www.go.dev <3",
+		Entities: []social.Entity{
+			{Type: social.EntityMention, Value: "gopher", Start: 3, End: 10},
+			{Type: social.EntityHashtag, Value: "タグ", Start: 11, End: 14},
+			{Type: social.EntityURL, Value: "www.go.dev", DisplayURL: "go.dev", Start: 15, End: 25},
+		},
+	}
+
+	wantMD := `日本 [@gopher](https://example.com/gopher) [\#タグ](https://example.com/tags/%E3%82%BF%E3%82%B0) [go.dev](http://www.go.dev) \<3`
+	if got := Markdown(post); got != wantMD {
+		t.Errorf("expecting %q, got %q", wantMD, got)
+	}
+
+	wantHTML := `<p>日本 <a href="https://example.com/gopher" class="mention" rel="nofollow noopener noreferrer">@gopher</a>` +
+		` <a href="https://example.com/tags/%E3%82%BF%E3%82%B0" class="hashtag" rel="nofollow noopener noreferrer">#タグ</a>` +
+		` <a href="http://www.go.dev" class="url" rel="nofollow noopener noreferrer">go.dev</a> &lt;3</p>`
+	if got := HTML(post); got != wantHTML {
+		t.Errorf("expecting %q, got %q", wantHTML, got)
+	}
+
+	// Entities out of the contents or overlapping are rendered as text
+	post.Entities = append(post.Entities, social.Entity{Type: social.EntityURL, Value: "x", Start: 12, End: 40})
+	if got := Markdown(post); got != wantMD {
+		t.Errorf("expecting %q, got %q", wantMD, got)
+	}
+}
@@ -0,0 +1,169 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+// RetryPolicy is how the read calls of a session are retried on transient
+// provider errors, see WithRetry
+type RetryPolicy struct {
+	MaxAttempts int           // calls made at most, including the first one
+	BaseDelay   time.Duration // delay before the first retry, doubled on each retry
+	MaxDelay    time.Duration // longest delay between attempts
+	MaxWait     time.Duration // longest total delay of the retries of a call
+}
+
+// DefaultRetryPolicy is the retry policy of the sessions returned by
+// NewSession. Set MaxAttempts to 1 to disable retries.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	MaxWait:     30 * time.Second,
+}
+
+// WithRetry returns a session retrying the read calls which fail with a
+// transient error, ie. ErrProviderDown, a network error or a rate limit
+// which resets within MaxDelay. Delays are jittered exponential backoffs,
+// or the RetryAfter of rate limit errors.
+//
+// Writes are never retried, as a post which timed out may have been published.
+// The read calls without a context, eg. Search, can't be canceled while
+// waiting to retry, so set a MaxWait to bound how long they may block.
+func WithRetry(s ProviderSession, policy RetryPolicy) ProviderSession {
+	return &retrySession{ProviderSession: s, policy: policy}
+}
+
+// Retryable reports whether err is a transient error which may succeed
+// when the call is tried again, see Error.IsRetryable
+func Retryable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.IsRetryable()
+	}
+	return isNetError(err)
+}
+
+// isNetError reports whether err is a network error, other than the
+// cancellation of the call
+func isNetError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
+
+// Delay returns the delay before the given retry, starting at 1, or false
+// when the call should not be tried again
+func (p RetryPolicy) Delay(retry int, err error) (time.Duration, bool) {
+	if retry >= p.MaxAttempts || !Retryable(err) {
+		return 0, false
+	}
+
+	backoff := p.BaseDelay << uint(retry-1)
+	if backoff <= 0 || (p.MaxDelay > 0 && backoff > p.MaxDelay) {
+		backoff = p.MaxDelay
+	}
+	// Full jitter, so the sessions hitting a provider outage don't retry
+	// all at once
+	d := time.Duration(rand.Int63n(int64(backoff) + 1))
+
+	if IsRateLimit(err) {
+		d = RetryAfter(err, d)
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return 0, false
+	}
+	return d, true
+}
+
+// retry calls call until it succeeds or fails with an error which isn't
+// retried, or the next retry would wait past the policy MaxWait, or ctx is
+// done while waiting to retry, then with the ctx error
+func retry[T any](ctx context.Context, policy RetryPolicy, call func() (T, error)) (T, error) {
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		res, err := call()
+		d, ok := policy.Delay(attempt, err)
+		if !ok || (policy.MaxWait > 0 && waited+d > policy.MaxWait) {
+			return res, err
+		}
+		waited += d
+		if err := sleepCtx(ctx, d); err != nil {
+			return res, err
+		}
+	}
+}
+
+type retrySession struct {
+	ProviderSession
+	policy RetryPolicy
+}
+
+type page[T any] struct {
+	items  T
+	cursor *Cursor
+}
+
+func (s *retrySession) retryPage(ctx context.Context, fetch func(query Query) (social.Posts, *Cursor, error), query Query) (social.Posts, *Cursor, error) {
+	p, err := retry(ctx, s.policy, func() (page[social.Posts], error) {
+		posts, cursor, err := fetch(query)
+		return page[social.Posts]{posts, cursor}, err
+	})
+	return p.items, p.cursor, err
+}
+
+func (s *retrySession) retryUsers(fetch func(query Query) ([]*social.User, *Cursor, error), query Query) ([]*social.User, *Cursor, error) {
+	p, err := retry(context.Background(), s.policy, func() (page[[]*social.User], error) {
+		users, cursor, err := fetch(query)
+		return page[[]*social.User]{users, cursor}, err
+	})
+	return p.items, p.cursor, err
+}
+
+func (s *retrySession) Search(query Query) (social.Posts, *Cursor, error) {
+	return s.retryPage(context.Background(), s.ProviderSession.Search, query)
+}
+
+func (s *retrySession) GetFeed(query Query) (social.Posts, *Cursor, error) {
+	return s.retryPage(context.Background(), s.ProviderSession.GetFeed, query)
+}
+
+func (s *retrySession) GetPosts(query Query) (social.Posts, *Cursor, error) {
+	return s.retryPage(context.Background(), s.ProviderSession.GetPosts, query)
+}
+
+func (s *retrySession) GetUser(query Query) (*social.User, error) {
+	return retry(context.Background(), s.policy, func() (*social.User, error) {
+		return s.ProviderSession.GetUser(query)
+	})
+}
+
+func (s *retrySession) GetFriends(query Query) ([]*social.User, *Cursor, error) {
+	return s.retryUsers(s.ProviderSession.GetFriends, query)
+}
+
+func (s *retrySession) GetFollowers(query Query) ([]*social.User, *Cursor, error) {
+	return s.retryUsers(s.ProviderSession.GetFollowers, query)
+}
+
+func (s *retrySession) GetReplies(ctx context.Context, postID string, query Query) (social.Posts, *Cursor, error) {
+	return s.retryPage(ctx, func(query Query) (social.Posts, *Cursor, error) {
+		return s.ProviderSession.GetReplies(ctx, postID, query)
+	}, query)
+}
+
+func (s *retrySession) GetThread(ctx context.Context, postID string, query Query) (*social.Post, *Cursor, error) {
+	p, err := retry(ctx, s.policy, func() (page[*social.Post], error) {
+		root, cursor, err := s.ProviderSession.GetThread(ctx, postID, query)
+		return page[*social.Post]{root, cursor}, err
+	})
+	return p.items, p.cursor, err
+}
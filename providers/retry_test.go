@@ -0,0 +1,168 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+func TestRetryContext(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	_, err := retry(ctx, policy, func() (int, error) {
+		calls++
+		return 0, ErrProviderDown
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expecting the context error, got %v", err)
+	}
+	if calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("expecting the retry wait to end with the context, got %d calls in %v", calls, time.Since(start))
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	// Full jitter over the doubled backoffs, up to MaxDelay
+	for retry, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond} {
+		for i := 0; i < 100; i++ {
+			d, ok := policy.Delay(retry, ErrProviderDown)
+			if !ok || d < 0 || d > max {
+				t.Fatalf("expecting retry %d within [0, %v], got %v, %v", retry, max, d, ok)
+			}
+		}
+	}
+	policy.MaxAttempts = 10
+	for i := 0; i < 100; i++ {
+		if d, ok := policy.Delay(8, ErrProviderDown); !ok || d > time.Second {
+			t.Fatalf("expecting the backoff capped by MaxDelay, got %v, %v", d, ok)
+		}
+	}
+	if _, ok := policy.Delay(10, ErrProviderDown); ok {
+		t.Errorf("expecting no retry after MaxAttempts calls")
+	}
+
+	// Rate limits wait until they reset, unless it's beyond MaxDelay
+	rateLimit := &Error{Code: ErrHitRateLimit.Code, RetryAfter: 500 * time.Millisecond}
+	if d, ok := policy.Delay(1, rateLimit); !ok || d != 500*time.Millisecond {
+		t.Errorf("expecting the RetryAfter of the rate limit, got %v, %v", d, ok)
+	}
+	if _, ok := policy.Delay(1, ErrHitRateLimit.RetryAt(time.Now().Add(time.Minute))); ok {
+		t.Errorf("expecting no retry of a rate limit resetting after MaxDelay")
+	}
+	if _, ok := policy.Delay(1, ErrHitRateLimit); ok {
+		t.Errorf("expecting no retry of a rate limit without a reset time")
+	}
+
+	for _, err := range []error{
+		nil,
+		ErrPostNotFound,
+		ErrAuthFailed,
+		errors.New("failed"),
+		context.Canceled,
+		ErrInvalidAsset,
+	} {
+		if d, ok := policy.Delay(1, err); ok {
+			t.Errorf("expecting no retry of %v, got %v", err, d)
+		}
+	}
+	if _, ok := policy.Delay(1, &net.OpError{Op: "dial", Err: errors.New("refused")}); !ok {
+		t.Errorf("expecting a retry of a network error")
+	}
+}
+
+func TestRetryMaxWait(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, MaxDelay: time.Second, MaxWait: 50 * time.Millisecond}
+
+	calls := 0
+	_, err := retry(context.Background(), policy, func() (int, error) {
+		calls++
+		return 0, &Error{Code: ErrHitRateLimit.Code, RetryAfter: 20 * time.Millisecond}
+	})
+	if !errors.Is(err, ErrHitRateLimit) {
+		t.Errorf("expecting the last error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expecting 2 retries within MaxWait, got %d calls", calls)
+	}
+}
+
+// writeSession counts the calls of a session failing with ErrProviderDown
+type writeSession struct {
+	ProviderSession
+	calls map[string]int
+}
+
+func (s *writeSession) fail(method string) error {
+	s.calls[method]++
+	return ErrProviderDown
+}
+
+func (s *writeSession) Publish(ctx context.Context, req *PostRequest) (*social.Post, error) {
+	return nil, s.fail("Publish")
+}
+
+func (s *writeSession) DeletePost(ctx context.Context, id string) error {
+	return s.fail("DeletePost")
+}
+
+func (s *writeSession) EditPost(ctx context.Context, id string, req *PostRequest) (*social.Post, error) {
+	return nil, s.fail("EditPost")
+}
+
+func (s *writeSession) Like(ctx context.Context, postID string) error {
+	return s.fail("Like")
+}
+
+func (s *writeSession) Unlike(ctx context.Context, postID string) error {
+	return s.fail("Unlike")
+}
+
+func (s *writeSession) Share(ctx context.Context, postID string) (*social.Post, error) {
+	return nil, s.fail("Share")
+}
+
+func (s *writeSession) Follow(ctx context.Context, userID string) error {
+	return s.fail("Follow")
+}
+
+func (s *writeSession) Unfollow(ctx context.Context, userID string) error {
+	return s.fail("Unfollow")
+}
+
+func (s *writeSession) Search(query Query) (social.Posts, *Cursor, error) {
+	return nil, nil, s.fail("Search")
+}
+
+func TestRetryWrites(t *testing.T) {
+	ctx := context.Background()
+	inner := &writeSession{calls: map[string]int{}}
+	s := WithRetry(inner, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	s.Publish(ctx, &PostRequest{})
+	s.DeletePost(ctx, "1")
+	s.EditPost(ctx, "1", &PostRequest{})
+	s.Like(ctx, "1")
+	s.Unlike(ctx, "1")
+	s.Share(ctx, "1")
+	s.Follow(ctx, "1")
+	s.Unfollow(ctx, "1")
+	for _, method := range []string{"Publish", "DeletePost", "EditPost", "Like", "Unlike", "Share", "Follow", "Unfollow"} {
+		if inner.calls[method] != 1 {
+			t.Errorf("expecting %s to be called once, got %d calls", method, inner.calls[method])
+		}
+	}
+
+	if _, _, err := s.Search(Query{}); !errors.Is(err, ErrProviderDown) || inner.calls["Search"] != 3 {
+		t.Errorf("expecting Search to be retried, got %d calls, %v", inner.calls["Search"], err)
+	}
+}
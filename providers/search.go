@@ -0,0 +1,538 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-social/social"
+)
+
+// SearchDateLayout is the date format of the since: and until: operators
+const SearchDateLayout = "2006-01-02"
+
+// SearchNode is a node of a parsed search query. See ParseSearch for the
+// query language.
+type SearchNode interface {
+	// String returns the node in the search query language
+	String() string
+
+	match(post *social.Post, doc *filterDoc) bool
+}
+
+// SearchAnd matches when all its nodes match
+type SearchAnd struct {
+	Nodes []SearchNode
+}
+
+// SearchOr matches when any of its nodes match
+type SearchOr struct {
+	Nodes []SearchNode
+}
+
+// SearchNot negates its node
+type SearchNot struct {
+	Node SearchNode
+}
+
+type SearchTermKind int
+
+const (
+	SearchWord SearchTermKind = iota
+	SearchPhrase
+	SearchUser
+	SearchTag
+)
+
+// SearchTerm is a keyword, "quoted phrase", @username or #tag
+type SearchTerm struct {
+	Kind  SearchTermKind
+	Value string // without quotes or prefix
+}
+
+// SearchOperator is a name:value operator, see SearchOperators
+type SearchOperator struct {
+	Name  string
+	Value string
+}
+
+// SearchOperators are the supported operators and their value validation
+var SearchOperators = map[string]func(value string) (string, error){
+	"lang":       parseLangOp,
+	"since":      parseDateOp,
+	"until":      parseDateOp,
+	"has":        parseHasOp,
+	"min_likes":  parseCountOp,
+	"min_shares": parseCountOp,
+}
+
+// ParseSearch parses a search query into its syntax tree, and returns nil
+// for an empty query. The query language is made of:
+//
+//	words, "quoted phrases", @usernames and #tags
+//	lang:en, since:2006-01-02, until:2006-01-02 (exclusive)
+//	has:media, has:link, min_likes:10, min_shares:10
+//	-term to exclude a term, operator or group
+//	a OR b, and (grouping) with parentheses
+//
+// Space separated terms must all match, and bind tighter than OR.
+func ParseSearch(q string) (SearchNode, error) {
+	p := &searchParser{tokens: lexSearch(q)}
+	if len(p.tokens) == 0 {
+		return nil, nil
+	}
+	for _, tok := range p.tokens {
+		if tok.kind == tokError {
+			return nil, ErrInvalidQuery.Err(fmt.Errorf("search: %s", tok.value))
+		}
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.peek().value)
+	}
+	return node, nil
+}
+
+// MatchSearch evaluates the search expression on a post, client-side
+func MatchSearch(node SearchNode, post *social.Post) bool {
+	if node == nil {
+		return true
+	}
+	return node.match(post, newFilterDoc(post))
+}
+
+// MatchPosts returns the posts matching the search expression
+func MatchPosts(posts social.Posts, node SearchNode) social.Posts {
+	if node == nil {
+		return posts
+	}
+	var res social.Posts
+	for _, p := range posts {
+		if MatchSearch(node, p) {
+			res.Add(p)
+		}
+	}
+	return res
+}
+
+// matchOperators are the operators evaluated by MatchSearch, the ones
+// added to SearchOperators can only be searched natively
+var matchOperators = map[string]bool{
+	"lang":       true,
+	"since":      true,
+	"until":      true,
+	"has":        true,
+	"min_likes":  true,
+	"min_shares": true,
+}
+
+// Matchable reports whether the expression can be evaluated client-side
+// with MatchSearch
+func Matchable(node SearchNode) bool {
+	switch n := node.(type) {
+	case *SearchAnd:
+		for _, c := range n.Nodes {
+			if !Matchable(c) {
+				return false
+			}
+		}
+	case *SearchOr:
+		for _, c := range n.Nodes {
+			if !Matchable(c) {
+				return false
+			}
+		}
+	case *SearchNot:
+		return Matchable(n.Node)
+	case *SearchOperator:
+		return matchOperators[n.Name]
+	}
+	return true
+}
+
+// CompileSearch translates a search expression into a provider's native
+// search syntax with compile, which returns ErrUnsupported for nodes the
+// provider can't express. Such nodes at the top level are left out of the
+// native query and returned, to be applied client-side with MatchPosts.
+// An error is returned when they can't be applied client-side either.
+// The native query is compiled from the AND of the supported nodes.
+func CompileSearch(expr SearchNode, compile func(node SearchNode) (string, error)) (string, SearchNode, error) {
+	if expr == nil {
+		return "", nil, nil
+	}
+
+	nodes := andNodes(expr)
+
+	var native, residual []SearchNode
+	for _, node := range nodes {
+		_, err := compile(node)
+		if errors.Is(err, ErrUnsupported) {
+			if !Matchable(node) {
+				return "", nil, ErrUnsupported.Err(fmt.Errorf("search: %q isn't supported by the provider", node.String()))
+			}
+			residual = append(residual, node)
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		native = append(native, node)
+	}
+
+	// The native nodes are compiled together, for the provider to group
+	// them as its syntax requires
+	var q string
+	if node := andNode(native); node != nil {
+		var err error
+		if q, err = compile(node); err != nil {
+			return "", nil, err
+		}
+	}
+	return q, andNode(residual), nil
+}
+
+// andNode returns the node matching all the nodes, nil when there's none
+func andNode(nodes []SearchNode) SearchNode {
+	switch len(nodes) {
+	case 0:
+		return nil
+	case 1:
+		return nodes[0]
+	}
+	return &SearchAnd{Nodes: nodes}
+}
+
+// andNodes returns the nodes which must all match in an expression
+func andNodes(expr SearchNode) []SearchNode {
+	switch n := expr.(type) {
+	case nil:
+		return nil
+	case *SearchAnd:
+		return n.Nodes
+	}
+	return []SearchNode{expr}
+}
+
+func (n *SearchAnd) String() string {
+	parts := make([]string, len(n.Nodes))
+	for i, c := range n.Nodes {
+		parts[i] = c.String()
+		if _, ok := c.(*SearchOr); ok {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (n *SearchAnd) match(post *social.Post, doc *filterDoc) bool {
+	for _, c := range n.Nodes {
+		if !c.match(post, doc) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *SearchOr) String() string {
+	parts := make([]string, len(n.Nodes))
+	for i, c := range n.Nodes {
+		parts[i] = c.String()
+		if _, ok := c.(*SearchAnd); ok {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " OR ")
+}
+
+func (n *SearchOr) match(post *social.Post, doc *filterDoc) bool {
+	for _, c := range n.Nodes {
+		if c.match(post, doc) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *SearchNot) String() string {
+	switch n.Node.(type) {
+	case *SearchAnd, *SearchOr:
+		return "-(" + n.Node.String() + ")"
+	}
+	return "-" + n.Node.String()
+}
+
+func (n *SearchNot) match(post *social.Post, doc *filterDoc) bool {
+	return !n.Node.match(post, doc)
+}
+
+func (n *SearchTerm) String() string {
+	switch n.Kind {
+	case SearchPhrase:
+		return `"` + n.Value + `"`
+	case SearchUser:
+		return "@" + n.Value
+	case SearchTag:
+		return "#" + n.Value
+	}
+	return n.Value
+}
+
+func (n *SearchTerm) match(post *social.Post, doc *filterDoc) bool {
+	term, ok := newFilterTerm(n.String())
+	return !ok || doc.match(term)
+}
+
+func (n *SearchOperator) String() string {
+	return n.Name + ":" + n.Value
+}
+
+func (n *SearchOperator) match(post *social.Post, doc *filterDoc) bool {
+	switch n.Name {
+	case "lang":
+		return strings.EqualFold(post.Lang, n.Value)
+	case "since", "until":
+		date, _ := time.Parse(SearchDateLayout, n.Value)
+		if post.PublishedAt == nil {
+			return false
+		}
+		if n.Name == "since" {
+			return !post.PublishedAt.Before(date)
+		}
+		return post.PublishedAt.Before(date)
+	case "has":
+		if n.Value == "link" {
+			return len(post.Links) > 0 || strings.Contains(post.Contents, "://")
+		}
+		return len(post.Media) > 0
+	case "min_likes":
+		min, _ := strconv.Atoi(n.Value)
+		return int(post.NumLikes) >= min
+	case "min_shares":
+		min, _ := strconv.Atoi(n.Value)
+		return int(post.NumShares) >= min
+	}
+	return false
+}
+
+func parseLangOp(value string) (string, error) {
+	if len(value) < 2 || len(value) > 8 {
+		return "", fmt.Errorf("invalid language %q", value)
+	}
+	return strings.ToLower(value), nil
+}
+
+func parseDateOp(value string) (string, error) {
+	if _, err := time.Parse(SearchDateLayout, value); err != nil {
+		return "", fmt.Errorf("invalid date %q, expecting YYYY-MM-DD", value)
+	}
+	return value, nil
+}
+
+func parseHasOp(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "media":
+		return "media", nil
+	case "link", "links":
+		return "link", nil
+	}
+	return "", fmt.Errorf("invalid has:%s, expecting has:media or has:link", value)
+}
+
+func parseCountOp(value string) (string, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("invalid count %q", value)
+	}
+	return strconv.Itoa(n), nil
+}
+
+type searchTokenKind int
+
+const (
+	tokWord searchTokenKind = iota
+	tokPhrase
+	tokNot
+	tokOr
+	tokLParen
+	tokRParen
+	tokError
+)
+
+type searchToken struct {
+	kind  searchTokenKind
+	value string
+}
+
+func lexSearch(q string) []searchToken {
+	var tokens []searchToken
+	rs := []rune(q)
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, searchToken{tokLParen, "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, searchToken{tokRParen, ")"})
+			i++
+		case r == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) && rs[i+1] != ')':
+			tokens = append(tokens, searchToken{tokNot, "-"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			if end == len(rs) {
+				return append(tokens, searchToken{tokError, "unterminated quoted phrase"})
+			}
+			if phrase := strings.TrimSpace(string(rs[i+1 : end])); phrase != "" {
+				tokens = append(tokens, searchToken{tokPhrase, phrase})
+			}
+			i = end + 1
+		default:
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) && rs[end] != '(' && rs[end] != ')' {
+				end++
+			}
+			word := string(rs[i:end])
+			if word == "OR" {
+				tokens = append(tokens, searchToken{tokOr, word})
+			} else {
+				tokens = append(tokens, searchToken{tokWord, word})
+			}
+			i = end
+		}
+	}
+	return tokens
+}
+
+type searchParser struct {
+	tokens []searchToken
+	pos    int
+}
+
+func (p *searchParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *searchParser) peek() searchToken {
+	return p.tokens[p.pos]
+}
+
+func (p *searchParser) errorf(format string, args ...interface{}) error {
+	return ErrInvalidQuery.Err(fmt.Errorf("search: "+format, args...))
+}
+
+func (p *searchParser) parseOr() (SearchNode, error) {
+	var nodes []SearchNode
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		if p.done() || p.peek().kind != tokOr {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &SearchOr{Nodes: nodes}, nil
+}
+
+func (p *searchParser) parseAnd() (SearchNode, error) {
+	var nodes []SearchNode
+	for !p.done() && p.peek().kind != tokOr && p.peek().kind != tokRParen {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, p.errorf("missing search term")
+	case 1:
+		return nodes[0], nil
+	}
+	return &SearchAnd{Nodes: nodes}, nil
+}
+
+func (p *searchParser) parseUnary() (SearchNode, error) {
+	if p.peek().kind == tokNot {
+		p.pos++
+		if p.done() {
+			return nil, p.errorf("missing search term after -")
+		}
+		node, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &SearchNot{Node: node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *searchParser) parsePrimary() (SearchNode, error) {
+	tok := p.peek()
+	p.pos++
+
+	switch tok.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.done() || p.peek().kind != tokRParen {
+			return nil, p.errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+
+	case tokPhrase:
+		return &SearchTerm{Kind: SearchPhrase, Value: tok.value}, nil
+
+	case tokWord:
+		return parseSearchWord(tok.value)
+	}
+
+	return nil, p.errorf("unexpected %q", tok.value)
+}
+
+func parseSearchWord(word string) (SearchNode, error) {
+	if len(word) > 1 {
+		switch word[0] {
+		case '@':
+			return &SearchTerm{Kind: SearchUser, Value: word[1:]}, nil
+		case '#':
+			return &SearchTerm{Kind: SearchTag, Value: word[1:]}, nil
+		}
+	}
+
+	if i := strings.Index(word, ":"); i > 0 {
+		name := strings.ToLower(word[:i])
+		if validate, ok := SearchOperators[name]; ok {
+			value, err := validate(word[i+1:])
+			if err != nil {
+				return nil, ErrInvalidQuery.Err(fmt.Errorf("search: %s", err))
+			}
+			return &SearchOperator{Name: name, Value: value}, nil
+		}
+	}
+
+	return &SearchTerm{Kind: SearchWord, Value: word}, nil
+}
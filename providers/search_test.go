@@ -0,0 +1,160 @@
+package providers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		q    string
+		want string // String() of the tree, with the groups explicit
+	}{
+		{"", ""},
+		{"go", "go"},
+		{"go rust", "go rust"},
+		{`"hello world" @user #tag`, `"hello world" @user #tag`},
+		{"a b OR c", "(a b) OR c"},
+		{"a (b OR c)", "a (b OR c)"},
+		{"(a b) OR c", "(a b) OR c"},
+		{"-a -(b OR c)", "-a -(b OR c)"},
+		{"a - b", "a - b"},
+		{"LANG:EN has:links min_likes:007", "lang:en has:link min_likes:7"},
+		{"since:2020-01-02 until:2020-02-01", "since:2020-01-02 until:2020-02-01"},
+		{"url:http://x.com", "url:http://x.com"},
+		{"@ # or", "@ # or"},
+	}
+	for _, tt := range tests {
+		node, err := ParseSearch(tt.q)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.q, err)
+			continue
+		}
+		got := ""
+		if node != nil {
+			got = node.String()
+		}
+		if got != tt.want {
+			t.Errorf("%q: expecting %q, got %q", tt.q, tt.want, got)
+		}
+	}
+}
+
+func TestParseSearchPrecedence(t *testing.T) {
+	node, _ := ParseSearch("a b OR c")
+	or, ok := node.(*SearchOr)
+	if !ok || len(or.Nodes) != 2 {
+		t.Fatalf("expecting an OR of 2 nodes, got %#v", node)
+	}
+	if and, ok := or.Nodes[0].(*SearchAnd); !ok || len(and.Nodes) != 2 {
+		t.Errorf("expecting AND to bind tighter than OR, got %#v", or.Nodes[0])
+	}
+}
+
+func TestParseSearchErrors(t *testing.T) {
+	for _, q := range []string{
+		`"unterminated`,
+		"(a OR b",
+		"a)",
+		"OR a",
+		"a OR",
+		"()",
+		"since:yesterday",
+		"lang:e",
+		"has:video",
+		"min_likes:-1",
+	} {
+		if _, err := ParseSearch(q); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: expecting ErrInvalidQuery, got %v", q, err)
+		}
+	}
+}
+
+func TestMatchSearch(t *testing.T) {
+	published := time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC)
+	post := &social.Post{
+		Author:      social.User{Username: "gopher"},
+		Contents:    "Hello Wörld, #golang is fun https://go.dev",
+		Lang:        "en",
+		Tags:        []string{"golang"},
+		Links:       []string{"https://go.dev"},
+		NumLikes:    10,
+		PublishedAt: &published,
+	}
+
+	tests := []struct {
+		q    string
+		want bool
+	}{
+		{"hello", true},
+		{"world", true},
+		{`"hello world"`, true},
+		{"#golang @gopher", true},
+		{"-#golang", false},
+		{"rust OR fun", true},
+		{"rust OR (hello -fun)", false},
+		{"lang:en has:link min_likes:10", true},
+		{"has:media", false},
+		{"min_likes:11", false},
+		{"since:2020-01-02 until:2020-01-03", true},
+		{"until:2020-01-02", false},
+		{"-since:2020-01-03", true},
+	}
+	for _, tt := range tests {
+		node, err := ParseSearch(tt.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := MatchSearch(node, post); got != tt.want {
+			t.Errorf("%q: expecting %v, got %v", tt.q, tt.want, got)
+		}
+	}
+}
+
+func TestCompileSearch(t *testing.T) {
+	// A provider searching words only
+	var compile func(node SearchNode) (string, error)
+	compile = func(node SearchNode) (string, error) {
+		switch n := node.(type) {
+		case *SearchAnd:
+			s := ""
+			for _, c := range n.Nodes {
+				cs, err := compile(c)
+				if err != nil {
+					return "", err
+				}
+				s += " " + cs
+			}
+			return s[1:], nil
+		case *SearchTerm:
+			if n.Kind == SearchWord {
+				return n.Value, nil
+			}
+		}
+		return "", ErrUnsupported
+	}
+
+	node, _ := ParseSearch("a b #tag has:media")
+	q, residual, err := CompileSearch(node, compile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != "a b" || residual == nil || residual.String() != "#tag has:media" {
+		t.Errorf("unexpected native %q, residual %v", q, residual)
+	}
+
+	// Operators which can't be matched client-side are reported
+	SearchOperators["place"] = func(value string) (string, error) { return value, nil }
+	defer delete(SearchOperators, "place")
+
+	node, _ = ParseSearch("a -place:paris")
+	if Matchable(node) {
+		t.Errorf("expecting place: not to be matchable")
+	}
+	if _, _, err := CompileSearch(node, compile); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expecting ErrUnsupported, got %v", err)
+	}
+}
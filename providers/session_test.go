@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/go-social/social"
+)
+
+func init() {
+	CursorKey = []byte("0123456789abcdef0123456789abcdef")
+}
+
+// nextToken returns the next page token of the cursor
+func nextToken(t *testing.T, cursor *Cursor) string {
+	t.Helper()
+	token, err := cursor.NextToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// encodeCursor returns the cursor token of the query
+func encodeCursor(t *testing.T, query Query) string {
+	t.Helper()
+	token, err := EncodeCursor(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// pagedSession is a fake session searching posts numbered from n down to
+// 1, newest first, paged by until_id
+type pagedSession struct {
+	ProviderSession
+	id    string
+	posts social.Posts
+	calls int
+}
+
+func newPagedSession(id string, n int) *pagedSession {
+	s := &pagedSession{id: id}
+	for i := n; i > 0; i-- {
+		s.posts.Add(&social.Post{ID: strconv.Itoa(i), Provider: id, Contents: "post " + strconv.Itoa(i)})
+	}
+	return s
+}
+
+func (s *pagedSession) ID() string {
+	return s.id
+}
+
+func (s *pagedSession) Credentials() social.Credentials {
+	return nil
+}
+
+func (s *pagedSession) Search(query Query) (social.Posts, *Cursor, error) {
+	s.calls++
+
+	start := 0
+	if query.UntilID != "" {
+		for i, p := range s.posts {
+			if p.ID == query.UntilID {
+				start = i + 1
+			}
+		}
+	}
+	end := start + query.Limit
+	if end > len(s.posts) {
+		end = len(s.posts)
+	}
+	page := s.posts[start:end]
+
+	prev, next := "", ""
+	if len(page) > 0 {
+		prev = page[0].ID
+		if end < len(s.posts) {
+			next = page[len(page)-1].ID
+		}
+	}
+	return page, NewCursor(query, prev, next), nil
+}
@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OAuth records the funnel metrics of the OAuth flows, how many are
+// started, completed and failed by error code, and the spans of the token
+// exchanges
+type OAuth struct {
+	tracer    trace.Tracer
+	started   metric.Int64Counter
+	completed metric.Int64Counter
+	failed    metric.Int64Counter
+}
+
+func NewOAuth(opts ...Option) *OAuth {
+	c := newConfig(opts)
+	meter := c.meterProvider.Meter(instrumentationName)
+
+	started, _ := meter.Int64Counter("social.oauth.started",
+		metric.WithDescription("OAuth flows started"),
+		metric.WithUnit("{flow}"))
+	completed, _ := meter.Int64Counter("social.oauth.completed",
+		metric.WithDescription("OAuth flows completed"),
+		metric.WithUnit("{flow}"))
+	failed, _ := meter.Int64Counter("social.oauth.failed",
+		metric.WithDescription("OAuth flows failed, by error code"),
+		metric.WithUnit("{flow}"))
+
+	return &OAuth{
+		tracer:    c.tracerProvider.Tracer(instrumentationName),
+		started:   started,
+		completed: completed,
+		failed:    failed,
+	}
+}
+
+func (o *OAuth) Started(ctx context.Context, providerID string) {
+	o.started.Add(ctx, 1, metric.WithAttributes(ProviderKey.String(providerID)))
+}
+
+func (o *OAuth) Completed(ctx context.Context, providerID string) {
+	o.completed.Add(ctx, 1, metric.WithAttributes(ProviderKey.String(providerID)))
+}
+
+func (o *OAuth) Failed(ctx context.Context, providerID string, err error) {
+	attrs := append([]attribute.KeyValue{ProviderKey.String(providerID)}, errorAttrs(err)...)
+	o.failed.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// Done records Completed, or Failed for a non-nil err
+func (o *OAuth) Done(ctx context.Context, providerID string, err error) {
+	if err != nil {
+		o.Failed(ctx, providerID, err)
+	} else {
+		o.Completed(ctx, providerID)
+	}
+}
+
+// StartSpan starts the span of an OAuth step, ie. the token exchange. The
+// returned func ends the span with the result of the step.
+func (o *OAuth) StartSpan(ctx context.Context, providerID string, name string) (context.Context, func(err error)) {
+	ctx, span := o.tracer.Start(ctx, "social.oauth."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(ProviderKey.String(providerID)))
+	return ctx, func(err error) {
+		endSpan(span, err)
+	}
+}
@@ -0,0 +1,198 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns a session middleware recording a span, a call count,
+// a latency and a result count for every session call. Register it with
+// providers.Use, or per session with providers.WithMiddleware.
+//
+// Most session reads don't take a context, their spans are started from the
+// background context.
+func Middleware(opts ...Option) providers.Middleware {
+	c := newConfig(opts)
+	tracer := c.tracerProvider.Tracer(instrumentationName)
+	in := newInstruments(c.meterProvider.Meter(instrumentationName))
+
+	return func(s providers.ProviderSession) providers.ProviderSession {
+		return &session{ProviderSession: s, tracer: tracer, in: in}
+	}
+}
+
+type session struct {
+	providers.ProviderSession
+	tracer trace.Tracer
+	in     *instruments
+}
+
+// call records the span and metrics of a session method call. The call
+// returns its result count, or -1 when it doesn't return a list.
+func (s *session) call(ctx context.Context, method string, attrs []attribute.KeyValue, call func(ctx context.Context) (int, *providers.Cursor, error)) {
+	attrs = append([]attribute.KeyValue{
+		ProviderKey.String(s.ID()),
+		MethodKey.String(method),
+	}, attrs...)
+
+	start := time.Now()
+	ctx, span := s.tracer.Start(ctx, "social."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	count, cursor, err := call(ctx)
+
+	if err == nil && count >= 0 {
+		span.SetAttributes(ResultCountKey.Int(count))
+		span.SetAttributes(ResultMoreKey.Bool(cursor.HasNext()))
+	}
+	endSpan(span, err)
+
+	// Metrics only get the low cardinality attributes
+	s.in.record(ctx, start, attrs[:2:2], count, err)
+}
+
+func (s *session) posts(method string, fetch func(query providers.Query) (social.Posts, *providers.Cursor, error), query providers.Query) (posts social.Posts, cursor *providers.Cursor, err error) {
+	s.call(context.Background(), method, queryAttrs(query), func(ctx context.Context) (int, *providers.Cursor, error) {
+		posts, cursor, err = fetch(query)
+		return len(posts), cursor, err
+	})
+	return
+}
+
+func (s *session) users(method string, fetch func(query providers.Query) ([]*social.User, *providers.Cursor, error), query providers.Query) (users []*social.User, cursor *providers.Cursor, err error) {
+	s.call(context.Background(), method, queryAttrs(query), func(ctx context.Context) (int, *providers.Cursor, error) {
+		users, cursor, err = fetch(query)
+		return len(users), cursor, err
+	})
+	return
+}
+
+func (s *session) GetReplies(ctx context.Context, postID string, query providers.Query) (posts social.Posts, cursor *providers.Cursor, err error) {
+	s.call(ctx, "GetReplies", queryAttrs(query), func(ctx context.Context) (int, *providers.Cursor, error) {
+		posts, cursor, err = s.ProviderSession.GetReplies(ctx, postID, query)
+		return len(posts), cursor, err
+	})
+	return
+}
+
+func (s *session) GetThread(ctx context.Context, postID string, query providers.Query) (root *social.Post, cursor *providers.Cursor, err error) {
+	s.call(ctx, "GetThread", queryAttrs(query), func(ctx context.Context) (int, *providers.Cursor, error) {
+		root, cursor, err = s.ProviderSession.GetThread(ctx, postID, query)
+		return threadSize(root), cursor, err
+	})
+	return
+}
+
+// threadSize returns the number of replies of a conversation tree
+func threadSize(root *social.Post) int {
+	if root == nil {
+		return 0
+	}
+	n := len(root.Replies)
+	for _, r := range root.Replies {
+		n += threadSize(r)
+	}
+	return n
+}
+
+func (s *session) Publish(ctx context.Context, req *providers.PostRequest) (post *social.Post, err error) {
+	attrs := []attribute.KeyValue{
+		MediaCountKey.Int(len(req.Media)),
+		PostReplyKey.Bool(req.ReplyTo != ""),
+	}
+	s.call(ctx, "Publish", attrs, func(ctx context.Context) (int, *providers.Cursor, error) {
+		post, err = s.ProviderSession.Publish(ctx, req)
+		return -1, nil, err
+	})
+	return
+}
+
+// write records the span and metrics of a session write returning no
+// result
+func (s *session) write(ctx context.Context, method string, call func(ctx context.Context) error) (err error) {
+	s.call(ctx, method, nil, func(ctx context.Context) (int, *providers.Cursor, error) {
+		err = call(ctx)
+		return -1, nil, err
+	})
+	return
+}
+
+func (s *session) DeletePost(ctx context.Context, id string) error {
+	return s.write(ctx, "DeletePost", func(ctx context.Context) error {
+		return s.ProviderSession.DeletePost(ctx, id)
+	})
+}
+
+func (s *session) EditPost(ctx context.Context, id string, req *providers.PostRequest) (post *social.Post, err error) {
+	s.call(ctx, "EditPost", []attribute.KeyValue{MediaCountKey.Int(len(req.Media))}, func(ctx context.Context) (int, *providers.Cursor, error) {
+		post, err = s.ProviderSession.EditPost(ctx, id, req)
+		return -1, nil, err
+	})
+	return
+}
+
+func (s *session) Like(ctx context.Context, postID string) error {
+	return s.write(ctx, "Like", func(ctx context.Context) error {
+		return s.ProviderSession.Like(ctx, postID)
+	})
+}
+
+func (s *session) Unlike(ctx context.Context, postID string) error {
+	return s.write(ctx, "Unlike", func(ctx context.Context) error {
+		return s.ProviderSession.Unlike(ctx, postID)
+	})
+}
+
+func (s *session) Share(ctx context.Context, postID string) (post *social.Post, err error) {
+	s.call(ctx, "Share", nil, func(ctx context.Context) (int, *providers.Cursor, error) {
+		post, err = s.ProviderSession.Share(ctx, postID)
+		return -1, nil, err
+	})
+	return
+}
+
+func (s *session) Follow(ctx context.Context, userID string) error {
+	return s.write(ctx, "Follow", func(ctx context.Context) error {
+		return s.ProviderSession.Follow(ctx, userID)
+	})
+}
+
+func (s *session) Unfollow(ctx context.Context, userID string) error {
+	return s.write(ctx, "Unfollow", func(ctx context.Context) error {
+		return s.ProviderSession.Unfollow(ctx, userID)
+	})
+}
+
+func (s *session) Search(query providers.Query) (social.Posts, *providers.Cursor, error) {
+	return s.posts("Search", s.ProviderSession.Search, query)
+}
+
+func (s *session) GetFeed(query providers.Query) (social.Posts, *providers.Cursor, error) {
+	return s.posts("GetFeed", s.ProviderSession.GetFeed, query)
+}
+
+func (s *session) GetPosts(query providers.Query) (social.Posts, *providers.Cursor, error) {
+	return s.posts("GetPosts", s.ProviderSession.GetPosts, query)
+}
+
+func (s *session) GetUser(query providers.Query) (user *social.User, err error) {
+	s.call(context.Background(), "GetUser", queryAttrs(query), func(ctx context.Context) (int, *providers.Cursor, error) {
+		user, err = s.ProviderSession.GetUser(query)
+		return -1, nil, err
+	})
+	return
+}
+
+func (s *session) GetFriends(query providers.Query) ([]*social.User, *providers.Cursor, error) {
+	return s.users("GetFriends", s.ProviderSession.GetFriends, query)
+}
+
+func (s *session) GetFollowers(query providers.Query) ([]*social.User, *providers.Cursor, error) {
+	return s.users("GetFollowers", s.ProviderSession.GetFollowers, query)
+}
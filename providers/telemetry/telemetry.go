@@ -0,0 +1,189 @@
+// Package telemetry records OpenTelemetry spans and metrics of the provider
+// session calls, with Middleware, and of the OAuth flows, with OAuth.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-social/social/providers"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/go-social/social/providers/telemetry"
+
+// Attribute keys of the spans and metrics
+const (
+	ProviderKey      = attribute.Key("social.provider")
+	MethodKey        = attribute.Key("social.method")
+	ErrorCodeKey     = attribute.Key("social.error.code")
+	ErrorCategoryKey = attribute.Key("social.error.category")
+	ResultCountKey   = attribute.Key("social.result.count")
+	ResultMoreKey    = attribute.Key("social.result.more") // whether there is a next page
+	MediaCountKey    = attribute.Key("social.media.count") // number of media files posted
+	PostReplyKey     = attribute.Key("social.post.reply")  // whether the post is a reply
+
+	QueryLimitKey  = attribute.Key("social.query.limit")
+	QuerySortKey   = attribute.Key("social.query.sort")
+	QuerySearchKey = attribute.Key("social.query.search") // shape of the search, without the terms
+	QueryFilterKey = attribute.Key("social.query.filter")
+	QueryUserKey   = attribute.Key("social.query.user")
+	QueryPagedKey  = attribute.Key("social.query.paged")
+	QuerySinceKey  = attribute.Key("social.query.since")
+	QueryUntilKey  = attribute.Key("social.query.until")
+	QueryLangKey   = attribute.Key("social.query.lang")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures the telemetry providers, the otel global ones are used
+// by default
+type Option func(*config)
+
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	if c.meterProvider == nil {
+		c.meterProvider = otel.GetMeterProvider()
+	}
+	return c
+}
+
+// errorAttrs returns the mapped provider error code and category of err
+func errorAttrs(err error) []attribute.KeyValue {
+	if err == nil {
+		return []attribute.KeyValue{ErrorCodeKey.Int(0)}
+	}
+	code := providers.ErrUnknown.Code
+	var e *providers.Error
+	if errors.As(err, &e) {
+		code = e.Code
+	}
+	return []attribute.KeyValue{
+		ErrorCodeKey.Int(code),
+		ErrorCategoryKey.String(providers.ErrorCategoryOf(err).String()),
+	}
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(errorAttrs(err)...)
+	span.End()
+}
+
+// queryAttrs returns the shape of a query, leaving out the search terms,
+// usernames and ids
+func queryAttrs(query providers.Query) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		QueryLimitKey.Int(query.Limit),
+		QuerySortKey.String(query.Sort),
+		QueryFilterKey.Bool(query.Filter != ""),
+		QueryUserKey.Bool(query.Username != "" || query.UserID != ""),
+		QueryPagedKey.Bool(query.SinceID != "" || query.UntilID != ""),
+		QuerySinceKey.Bool(!query.Since.IsZero()),
+		QueryUntilKey.Bool(!query.Until.IsZero()),
+	}
+	if query.Lang != "" {
+		attrs = append(attrs, QueryLangKey.String(query.Lang))
+	}
+	if s := SearchShape(query.Search.Expr); s != "" {
+		attrs = append(attrs, QuerySearchKey.String(s))
+	}
+	return attrs
+}
+
+// SearchShape returns the structure of a search expression with its terms
+// replaced by their kind, ie. `@user (word OR #tag) lang:`
+func SearchShape(node providers.SearchNode) string {
+	switch n := node.(type) {
+	case *providers.SearchAnd:
+		return joinShapes(n.Nodes, " ")
+	case *providers.SearchOr:
+		return "(" + joinShapes(n.Nodes, " OR ") + ")"
+	case *providers.SearchNot:
+		return "-" + SearchShape(n.Node)
+	case *providers.SearchTerm:
+		switch n.Kind {
+		case providers.SearchPhrase:
+			return `"phrase"`
+		case providers.SearchUser:
+			return "@user"
+		case providers.SearchTag:
+			return "#tag"
+		default:
+			return "word"
+		}
+	case *providers.SearchOperator:
+		return n.Name + ":"
+	}
+	return ""
+}
+
+func joinShapes(nodes []providers.SearchNode, sep string) string {
+	shapes := make([]string, len(nodes))
+	for i, n := range nodes {
+		shapes[i] = SearchShape(n)
+	}
+	return strings.Join(shapes, sep)
+}
+
+// instruments are the metrics of the session calls
+type instruments struct {
+	calls    metric.Int64Counter
+	duration metric.Float64Histogram
+	results  metric.Int64Histogram
+}
+
+func newInstruments(meter metric.Meter) *instruments {
+	// Instrument errors only come from invalid names or options, and
+	// return no-op instruments
+	calls, _ := meter.Int64Counter("social.provider.calls",
+		metric.WithDescription("Provider session calls"),
+		metric.WithUnit("{call}"))
+	duration, _ := meter.Float64Histogram("social.provider.call.duration",
+		metric.WithDescription("Duration of the provider session calls"),
+		metric.WithUnit("s"))
+	results, _ := meter.Int64Histogram("social.provider.call.results",
+		metric.WithDescription("Number of posts or users returned by the provider session calls"),
+		metric.WithUnit("{result}"))
+	return &instruments{calls: calls, duration: duration, results: results}
+}
+
+func (in *instruments) record(ctx context.Context, start time.Time, attrs []attribute.KeyValue, count int, err error) {
+	attrs = append(attrs, errorAttrs(err)...)
+	opt := metric.WithAttributes(attrs...)
+	in.calls.Add(ctx, 1, opt)
+	in.duration.Record(ctx, time.Since(start).Seconds(), opt)
+	if err == nil && count >= 0 {
+		in.results.Record(ctx, int64(count), opt)
+	}
+}
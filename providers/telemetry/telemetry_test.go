@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type testSession struct {
+	providers.ProviderSession
+	err error
+}
+
+func (s *testSession) ID() string {
+	return "test"
+}
+
+func (s *testSession) Search(query providers.Query) (social.Posts, *providers.Cursor, error) {
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	posts := social.Posts{{ID: "2"}, {ID: "1"}}
+	return posts, providers.NewCursor(query, "2", "1"), nil
+}
+
+func newTestProviders() (*tracetest.InMemoryExporter, *sdkmetric.ManualReader, []Option) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	return exporter, reader, []Option{
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	}
+}
+
+func spanAttrs(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// sumCounter returns the total of a counter, by error code
+func sumCounter(t *testing.T, reader *sdkmetric.ManualReader, name string) map[int64]int64 {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	sums := map[int64]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				code, _ := dp.Attributes.Value(ErrorCodeKey)
+				sums[code.AsInt64()] += dp.Value
+			}
+		}
+	}
+	return sums
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter, reader, opts := newTestProviders()
+
+	ts := &testSession{}
+	s := Middleware(opts...)(ts)
+	query := providers.NewQuery(url.Values{"q": {"@someone (golang OR #go)"}, "limit": {"10"}})
+
+	if _, _, err := s.Search(query); err != nil {
+		t.Fatal(err)
+	}
+	ts.err = providers.ErrHitRateLimit
+	if _, _, err := s.Search(query); err != providers.ErrHitRateLimit {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+
+	attrs := spanAttrs(spans[0])
+	if spans[0].Name != "social.Search" || attrs[ProviderKey].AsString() != "test" || attrs[MethodKey].AsString() != "Search" {
+		t.Errorf("unexpected span %s %v", spans[0].Name, attrs)
+	}
+	if attrs[ResultCountKey].AsInt64() != 2 || !attrs[ResultMoreKey].AsBool() {
+		t.Errorf("unexpected result attributes %v", attrs)
+	}
+	if got := attrs[QuerySearchKey].AsString(); got != "@user (word OR #tag)" {
+		t.Errorf("search shape %q, want %q", got, "@user (word OR #tag)")
+	}
+	if attrs[QueryLimitKey].AsInt64() != 10 {
+		t.Errorf("unexpected limit %v", attrs[QueryLimitKey])
+	}
+
+	attrs = spanAttrs(spans[1])
+	if attrs[ErrorCodeKey].AsInt64() != int64(providers.ErrHitRateLimit.Code) || attrs[ErrorCategoryKey].AsString() != "quota" {
+		t.Errorf("unexpected error attributes %v", attrs)
+	}
+
+	calls := sumCounter(t, reader, "social.provider.calls")
+	if calls[0] != 1 || calls[int64(providers.ErrHitRateLimit.Code)] != 1 {
+		t.Errorf("unexpected calls by error code %v", calls)
+	}
+}
+
+func TestOAuth(t *testing.T) {
+	exporter, reader, opts := newTestProviders()
+	o := NewOAuth(opts...)
+	ctx := context.Background()
+
+	o.Started(ctx, "test")
+	o.Started(ctx, "test")
+
+	_, end := o.StartSpan(ctx, "test", "Exchange")
+	end(nil)
+	o.Done(ctx, "test", nil)
+	o.Done(ctx, "test", providers.ErrAuthFailed)
+
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Name != "social.oauth.Exchange" {
+		t.Errorf("unexpected spans %v", spans)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	totals := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				totals[m.Name] += dp.Value
+				if m.Name == "social.oauth.failed" {
+					if code, _ := dp.Attributes.Value(ErrorCodeKey); code.AsInt64() != int64(providers.ErrAuthFailed.Code) {
+						t.Errorf("unexpected failed error code %v", code)
+					}
+				}
+			}
+		}
+	}
+	if totals["social.oauth.started"] != 2 || totals["social.oauth.completed"] != 1 || totals["social.oauth.failed"] != 1 {
+		t.Errorf("unexpected funnel %v", totals)
+	}
+}
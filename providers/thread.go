@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"sort"
+
+	"github.com/go-social/social"
+)
+
+// BuildThread nests the posts of a conversation under the root post, by
+// their ParentID, and returns the root. The replies are sorted oldest
+// first, and the posts which aren't part of the root conversation are left
+// out.
+//
+// The root and the replies are shallow copies, the posts passed may be
+// shared, ie. cached.
+func BuildThread(root *social.Post, posts social.Posts) *social.Post {
+	root = threadCopy(root)
+	byID := map[string]*social.Post{root.ID: root}
+	for _, p := range posts {
+		if _, ok := byID[p.ID]; ok {
+			continue
+		}
+		byID[p.ID] = threadCopy(p)
+	}
+
+	sorted := make(social.Posts, 0, len(byID))
+	for _, p := range byID {
+		if p != root {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return publishedBefore(sorted[i], sorted[j])
+	})
+
+	for _, p := range sorted {
+		if parent, ok := byID[p.ParentID]; ok && parent != p {
+			parent.Replies.Add(p)
+		}
+	}
+	return root
+}
+
+// threadCopy returns a shallow copy of the post, without replies
+func threadCopy(p *social.Post) *social.Post {
+	c := *p
+	c.Replies = nil
+	return &c
+}
+
+// publishedBefore orders posts by publication time, and by id for the same
+// time or when unknown
+func publishedBefore(a, b *social.Post) bool {
+	if a.PublishedAt != nil && b.PublishedAt != nil && !a.PublishedAt.Equal(*b.PublishedAt) {
+		return a.PublishedAt.Before(*b.PublishedAt)
+	}
+	if len(a.ID) != len(b.ID) {
+		return len(a.ID) < len(b.ID)
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-social/social"
+)
+
+// threadIDs returns the ids of the thread, with the replies of each post
+// in parentheses
+func threadIDs(p *social.Post) string {
+	s := p.ID
+	if len(p.Replies) > 0 {
+		s += "("
+		for i, r := range p.Replies {
+			if i > 0 {
+				s += " "
+			}
+			s += threadIDs(r)
+		}
+		s += ")"
+	}
+	return s
+}
+
+func TestBuildThread(t *testing.T) {
+	at := func(m int) *time.Time {
+		t := time.Date(2020, 1, 2, 0, m, 0, 0, time.UTC)
+		return &t
+	}
+	root := &social.Post{ID: "1", PublishedAt: at(0)}
+	posts := social.Posts{
+		{ID: "4", ParentID: "1", PublishedAt: at(3)},
+		{ID: "2", ParentID: "1", PublishedAt: at(1)},
+		{ID: "5", ParentID: "2", PublishedAt: at(5)},
+		{ID: "3", ParentID: "2", PublishedAt: at(2)},
+		{ID: "7", ParentID: "6"}, // orphans
+		{ID: "8", ParentID: "7"},
+		{ID: "2", ParentID: "1", PublishedAt: at(1)}, // duplicate
+		{ID: "1"},
+	}
+
+	thread := BuildThread(root, posts)
+	if got, want := threadIDs(thread), "1(2(3 5) 4)"; got != want {
+		t.Errorf("expecting %s, got %s", want, got)
+	}
+
+	// The posts passed aren't changed, so the thread can be built again,
+	// ie. from cached posts
+	if len(root.Replies) != 0 || len(posts[1].Replies) != 0 {
+		t.Errorf("expecting the posts passed to be left unchanged")
+	}
+	thread = BuildThread(root, posts)
+	if got, want := threadIDs(thread), "1(2(3 5) 4)"; got != want {
+		t.Errorf("expecting %s rebuilt, got %s", want, got)
+	}
+	if got := threadIDs(BuildThread(thread, posts)); got != "1(2(3 5) 4)" {
+		t.Errorf("expecting a built thread to be rebuilt the same, got %s", got)
+	}
+}
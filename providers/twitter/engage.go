@@ -0,0 +1,76 @@
+package twitter
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+// Like a tweet
+// Network docs: https://developer.twitter.com/en/docs/tweets/post-and-engage/api-reference/post-favorites-create
+func (p *Provider) Like(ctx context.Context, postID string) error {
+	id, err := parseID(postID)
+	if err != nil {
+		return err
+	}
+	_, err = p.api.Favorite(id)
+	return providerError(err)
+}
+
+// Unlike a tweet
+// Network docs: https://developer.twitter.com/en/docs/tweets/post-and-engage/api-reference/post-favorites-destroy
+func (p *Provider) Unlike(ctx context.Context, postID string) error {
+	id, err := parseID(postID)
+	if err != nil {
+		return err
+	}
+	_, err = p.api.Unfavorite(id)
+	return providerError(err)
+}
+
+// Share retweets a tweet
+// Network docs: https://developer.twitter.com/en/docs/tweets/post-and-engage/api-reference/post-statuses-retweet-id
+func (p *Provider) Share(ctx context.Context, postID string) (*social.Post, error) {
+	id, err := parseID(postID)
+	if err != nil {
+		return nil, err
+	}
+	tweet, err := p.api.Retweet(id, false)
+	if err != nil {
+		return nil, providerError(err)
+	}
+	return (Mapper{}).BuildPost(tweet), nil
+}
+
+// Follow a user
+// Network docs: https://developer.twitter.com/en/docs/accounts-and-users/follow-search-get-users/api-reference/post-friendships-create
+func (p *Provider) Follow(ctx context.Context, userID string) error {
+	id, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+	_, err = p.api.FollowUserId(id, nil)
+	return providerError(err)
+}
+
+// Unfollow a user
+// Network docs: https://developer.twitter.com/en/docs/accounts-and-users/follow-search-get-users/api-reference/post-friendships-destroy
+func (p *Provider) Unfollow(ctx context.Context, userID string) error {
+	id, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+	_, err = p.api.UnfollowUserId(id)
+	return providerError(err)
+}
+
+// parseID parses a tweet or user id
+func parseID(id string) (int64, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, providers.ErrInvalidQuery.Err(err)
+	}
+	return n, nil
+}
@@ -1,54 +1,67 @@
 package twitter
 
 import (
+	"errors"
+
 	"github.com/ChimeraCoder/anaconda"
 	"github.com/go-social/social/providers"
 )
 
+// Error codes anaconda doesn't name
+const (
+	errorNoStatusFound = 144
+)
+
+// twitter errors: https://dev.twitter.com/docs/error-codes-responses
+var apiErrors = map[int]*providers.Error{
+	anaconda.TwitterErrorCouldNotAuthenticate:    providers.ErrAuthFailed,
+	anaconda.TwitterErrorDoesNotExist:            providers.ErrInvalidQuery,
+	errorNoStatusFound:                           providers.ErrPostNotFound,
+	anaconda.TwitterErrorAccountSuspended:        providers.ErrBadAccount,
+	anaconda.TwitterErrorRateLimitExceeded:       providers.ErrHitRateLimit,
+	anaconda.TwitterErrorInvalidToken:            providers.ErrInvalidToken,
+	anaconda.TwitterErrorOverCapacity:            providers.ErrProviderDown,
+	anaconda.TwitterErrorInternalError:           providers.ErrProviderDown,
+	anaconda.TwitterErrorCouldNotAuthenticateYou: providers.ErrAuthFailed,
+	anaconda.TwitterErrorStatusIsADuplicate:      providers.ErrDuplicatePost,
+	anaconda.TwitterErrorBadAuthenticationData:   providers.ErrAuthFailed,
+	anaconda.TwitterErrorUserMustVerifyLogin:     providers.ErrMustReauth,
+}
+
 func providerError(err error) error {
 	if err == nil {
 		return nil
 	}
-	if e, ok := err.(*anaconda.ApiError); ok {
-		// twitter errors: https://dev.twitter.com/docs/error-codes-responses
-		if len(e.Decoded.Errors) > 0 {
-			apiErr := e.Decoded.Errors[0] // oh anaconda..
-
-			switch apiErr.Code {
-			case anaconda.TwitterErrorCouldNotAuthenticate:
-				return providers.ErrAuthFailed
-			case anaconda.TwitterErrorDoesNotExist:
-				return providers.ErrInvalidQuery
-			case anaconda.TwitterErrorAccountSuspended:
-				return providers.ErrBadAccount
-			case anaconda.TwitterErrorRateLimitExceeded:
-				return providers.ErrHitRateLimit
-			case anaconda.TwitterErrorInvalidToken:
-				return providers.ErrInvalidToken
-			case anaconda.TwitterErrorOverCapacity:
-				return providers.ErrProviderDown
-			case anaconda.TwitterErrorInternalError:
-				return providers.ErrProviderDown
-			case anaconda.TwitterErrorCouldNotAuthenticateYou:
-				return providers.ErrAuthFailed
-			case anaconda.TwitterErrorStatusIsADuplicate:
-				return providers.ErrWritingPost
-			case anaconda.TwitterErrorBadAuthenticationData:
-				return providers.ErrAuthFailed
-			case anaconda.TwitterErrorUserMustVerifyLogin:
-				return providers.ErrMustReauth
-			default:
-				return providers.ErrUnknown.Err(e)
-			}
-		} else {
-			// It seems that twitter doesn't return a "code" on the unauthorized error
-			if e.StatusCode == 401 {
-				return providers.ErrUnauthorizedQuery
-			} else {
-				return providers.ErrUnknown.Err(err)
-			}
-		}
-	} else {
+	// Calls held back by the rate limit transport
+	var perr *providers.Error
+	if errors.As(err, &perr) {
+		return perr
+	}
+
+	e, ok := err.(*anaconda.ApiError)
+	if !ok {
 		return providers.ErrUnknown.Err(err)
 	}
+
+	code := 0
+	if len(e.Decoded.Errors) > 0 {
+		code = e.Decoded.Errors[0].Code // oh anaconda..
+	}
+
+	if limited, reset := e.RateLimitCheck(); limited {
+		return providers.ErrHitRateLimit.ProviderErr(e, code, e.StatusCode).RetryAt(reset)
+	}
+
+	if perr, ok := apiErrors[code]; ok {
+		return perr.ProviderErr(e, code, e.StatusCode)
+	}
+
+	switch {
+	case e.StatusCode >= 500:
+		return providers.ErrProviderDown.ProviderErr(e, code, e.StatusCode)
+	case e.StatusCode == 401 && code == 0:
+		// It seems that twitter doesn't return a "code" on the unauthorized error
+		return providers.ErrUnauthorizedQuery.ProviderErr(e, code, e.StatusCode)
+	}
+	return providers.ErrUnknown.ProviderErr(e, code, e.StatusCode)
 }
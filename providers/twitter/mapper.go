@@ -2,6 +2,7 @@ package twitter
 
 import (
 	"fmt"
+	"html"
 	"path"
 	"regexp"
 	"strings"
@@ -41,9 +42,42 @@ func (m Mapper) BuildPost(tweet anaconda.Tweet) *social.Post {
 			NumFollowers: int32(tweet.User.FollowersCount),
 			NumFollowing: int32(tweet.User.FriendsCount),
 		},
-		Contents:  tweet.Text,
+		Contents:  displayText(tweet),
+		Lang:      tweet.Lang,
 		NumShares: int32(tweet.RetweetCount),
 		NumLikes:  int32(tweet.FavoriteCount),
+		Media:     m.BuildMedia(tweet.ExtendedEntities.Media),
+		ParentID:  tweet.InReplyToStatusIdStr,
+	}
+
+	// The mentions of a reply, before the display range, are still
+	// mentioned
+	if r := tweet.DisplayTextRange; len(r) == 2 {
+		for _, u := range tweet.Entities.User_mentions {
+			if len(u.Indices) == 2 && u.Indices[1] <= r[0] {
+				post.Mentions = append(post.Mentions, u.Screen_name)
+			}
+		}
+	}
+	providers.AddEntities(post, urlExpansions(tweet.Entities))
+
+	if tweet.QuotedStatus != nil {
+		post.QuotedPost = m.BuildPost(*tweet.QuotedStatus)
+	}
+
+	// The text of a retweet is the original one truncated after "RT @user:",
+	// the retweet has the original contents instead
+	if tweet.RetweetedStatus != nil {
+		shared := m.BuildPost(*tweet.RetweetedStatus)
+		post.SharedPost = shared
+		post.Contents = shared.Contents
+		post.Lang = shared.Lang
+		post.Tags = shared.Tags
+		post.Mentions = shared.Mentions
+		post.Links = shared.Links
+		post.Media = shared.Media
+		post.Entities = shared.Entities
+		post.QuotedPost = shared.QuotedPost
 	}
 
 	publishedAt, _ := providers.GetUTCTimeForLayout(tweet.CreatedAt, TimeLayout)
@@ -52,6 +86,85 @@ func (m Mapper) BuildPost(tweet anaconda.Tweet) *social.Post {
 	return post
 }
 
+// displayText returns the full text of a tweet within its display range,
+// without the mentions of a reply before it and the media link after it.
+// The text is html escaped, and the range counts the code points of the
+// unescaped text.
+func displayText(tweet anaconda.Tweet) string {
+	text := tweet.FullText
+	if text == "" {
+		text = tweet.Text
+	}
+	text = html.UnescapeString(text)
+	if r := tweet.DisplayTextRange; len(r) == 2 {
+		runes := []rune(text)
+		if 0 <= r[0] && r[0] <= r[1] && r[1] <= len(runes) {
+			text = string(runes[r[0]:r[1]])
+		}
+	}
+	return text
+}
+
+// urlExpansions returns the expanded urls of the t.co links of a tweet
+func urlExpansions(entities anaconda.Entities) map[string]providers.URLExpansion {
+	urls := map[string]providers.URLExpansion{}
+	for _, u := range entities.Urls {
+		urls[u.Url] = providers.URLExpansion{ExpandedURL: u.Expanded_url, DisplayURL: u.Display_url}
+	}
+	for _, m := range entities.Media {
+		urls[m.Url] = providers.URLExpansion{ExpandedURL: m.Expanded_url, DisplayURL: m.Display_url, Media: true}
+	}
+	return urls
+}
+
+// BuildMedia maps the media of the tweet extended_entities, which, unlike
+// the entities, has all the photos and the video variants
+func (m Mapper) BuildMedia(entities []anaconda.EntityMedia) []*social.Media {
+	var media []*social.Media
+	for _, e := range entities {
+		md := &social.Media{
+			URL:     e.Media_url_https,
+			Width:   e.Sizes.Large.W,
+			Height:  e.Sizes.Large.H,
+			AltText: e.ExtAltText,
+		}
+		switch e.Type {
+		case "photo":
+			md.Type = social.MediaImage
+		case "video":
+			md.Type = social.MediaVideo
+		case "animated_gif":
+			md.Type = social.MediaGIF
+		default:
+			continue
+		}
+		if md.Type != social.MediaImage {
+			// Media_url_https is the preview image of videos and gifs
+			md.ThumbnailURL = e.Media_url_https
+			md.URL = videoURL(e.VideoInfo.Variants)
+		}
+		media = append(media, md)
+	}
+	return media
+}
+
+// videoURL returns the url of the highest bitrate mp4 variant of a video
+func videoURL(variants []anaconda.Variant) string {
+	var best *anaconda.Variant
+	for i, v := range variants {
+		if v.ContentType != "video/mp4" {
+			continue
+		}
+		if best == nil || v.Bitrate > best.Bitrate {
+			best = &variants[i]
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Url
+}
+
 type UserMapper struct{}
 
 func (m UserMapper) BuildUsers(us []anaconda.User) []*social.User {
@@ -0,0 +1,80 @@
+package twitter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ChimeraCoder/anaconda"
+)
+
+func parseTweet(t *testing.T, data string) *anaconda.Tweet {
+	var tweet anaconda.Tweet
+	if err := json.Unmarshal([]byte(data), &tweet); err != nil {
+		t.Fatal(err)
+	}
+	return &tweet
+}
+
+func TestDisplayText(t *testing.T) {
+	// The range counts the unescaped text: "@bob " before it, and the
+	// media link after it
+	tweet := parseTweet(t, `{
+		"id_str": "2", "full_text": "@bob Tom &amp; Jerry &lt;3 😀 https://t.co/media",
+		"display_text_range": [5, 21],
+		"user": {"id_str": "1", "screen_name": "alice"},
+		"entities": {"user_mentions": [{"screen_name": "bob", "indices": [0, 4]}]}
+	}`)
+	post := Mapper{}.BuildPost(*tweet)
+	if want := "Tom & Jerry <3 😀"; post.Contents != want {
+		t.Errorf("expecting %q, got %q", want, post.Contents)
+	}
+	if len(post.Mentions) != 1 || post.Mentions[0] != "bob" {
+		t.Errorf("expecting the reply mention, got %v", post.Mentions)
+	}
+
+	// Out of range
+	tweet.DisplayTextRange = []int{5, 100}
+	if got := displayText(*tweet); got != "@bob Tom & Jerry <3 😀 https://t.co/media" {
+		t.Errorf("expecting the whole text, got %q", got)
+	}
+}
+
+func TestRetweet(t *testing.T) {
+	tweet := parseTweet(t, `{
+		"id_str": "3", "full_text": "RT @alice: Hello #golang &amp; more…",
+		"user": {"id_str": "2", "screen_name": "bob"},
+		"retweeted_status": {
+			"id_str": "1", "full_text": "Hello #golang &amp; more https://t.co/x", "lang": "en",
+			"user": {"id_str": "1", "screen_name": "alice"},
+			"entities": {"urls": [{"url": "https://t.co/x", "expanded_url": "https://go.dev", "display_url": "go.dev"}]}
+		}
+	}`)
+	post := Mapper{}.BuildPost(*tweet)
+	if post.SharedPost == nil || post.SharedPost.ID != "1" || post.Author.Username != "bob" {
+		t.Fatalf("unexpected retweet %+v", post)
+	}
+	if post.Contents != "Hello #golang & more https://t.co/x" || post.Lang != "en" {
+		t.Errorf("expecting the original contents, got %q", post.Contents)
+	}
+	if len(post.Tags) != 1 || post.Tags[0] != "golang" || len(post.Links) != 1 || post.Links[0] != "https://go.dev" {
+		t.Errorf("unexpected tags %v and links %v", post.Tags, post.Links)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tweet := parseTweet(t, `{
+		"id_str": "2", "full_text": "So true https://t.co/q",
+		"user": {"id_str": "2", "screen_name": "bob"},
+		"quoted_status": {
+			"id_str": "1", "full_text": "Go is fun",
+			"user": {"id_str": "1", "screen_name": "alice"}
+		}
+	}`)
+	post := Mapper{}.BuildPost(*tweet)
+	if post.QuotedPost == nil || post.QuotedPost.ID != "1" || post.QuotedPost.Contents != "Go is fun" {
+		t.Fatalf("unexpected quoted post %+v", post.QuotedPost)
+	}
+	if post.Contents != "So true https://t.co/q" || post.SharedPost != nil {
+		t.Errorf("unexpected quote %+v", post)
+	}
+}
@@ -0,0 +1,84 @@
+package twitter
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+const (
+	// maxImages is the number of images a tweet may have
+	maxImages = 4
+
+	// chunkSize is the size of the chunked upload segments, twitter
+	// accepts up to 5MB
+	chunkSize = 4 << 20
+)
+
+// uploadMedia uploads the media of a tweet and returns their media ids.
+// Images are sent in a single request, videos and gifs with the chunked
+// upload.
+//
+// TODO: the media alt texts are left out, anaconda doesn't support the
+// media/metadata/create endpoint.
+func (p *Provider) uploadMedia(ctx context.Context, media []*providers.MediaUpload) ([]string, error) {
+	var ids []string
+	for _, m := range media {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var id string
+		var err error
+		if m.Type == social.MediaImage {
+			id, err = p.uploadImage(m)
+		} else {
+			id, err = p.uploadChunked(ctx, m)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (p *Provider) uploadImage(m *providers.MediaUpload) (string, error) {
+	media, err := p.api.UploadMedia(base64.StdEncoding.EncodeToString(m.Data))
+	if err != nil {
+		return "", providerError(err)
+	}
+	return media.MediaIDString, nil
+}
+
+// uploadChunked uploads a video or gif with the INIT, APPEND and FINALIZE
+// commands of the chunked upload
+// Network docs: https://developer.twitter.com/en/docs/media/upload-media/uploading-media/chunked-media-upload
+func (p *Provider) uploadChunked(ctx context.Context, m *providers.MediaUpload) (string, error) {
+	chunked, err := p.api.UploadVideoInit(len(m.Data), m.ContentType)
+	if err != nil {
+		return "", providerError(err)
+	}
+	id := chunked.MediaIDString
+
+	for i, offset := 0, 0; offset < len(m.Data); i, offset = i+1, offset+chunkSize {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		end := offset + chunkSize
+		if end > len(m.Data) {
+			end = len(m.Data)
+		}
+		err := p.api.UploadVideoAppend(id, i, base64.StdEncoding.EncodeToString(m.Data[offset:end]))
+		if err != nil {
+			return "", providerError(err)
+		}
+	}
+
+	if _, err := p.api.UploadVideoFinalize(id); err != nil {
+		return "", providerError(err)
+	}
+	return id, nil
+}
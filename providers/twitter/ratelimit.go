@@ -0,0 +1,35 @@
+package twitter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-social/social/providers"
+)
+
+// parseRateLimit returns the rate limit of the endpoint from the
+// x-rate-limit-* headers of a twitter response
+// See: https://developer.twitter.com/en/docs/basics/rate-limiting
+func parseRateLimit(resp *http.Response) (providers.RateLimit, bool) {
+	var rl providers.RateLimit
+	h := resp.Header
+
+	limit, err := strconv.Atoi(h.Get("X-Rate-Limit-Limit"))
+	if err != nil {
+		return rl, false
+	}
+	remaining, err := strconv.Atoi(h.Get("X-Rate-Limit-Remaining"))
+	if err != nil {
+		return rl, false
+	}
+	reset, err := strconv.ParseInt(h.Get("X-Rate-Limit-Reset"), 10, 64)
+	if err != nil {
+		return rl, false
+	}
+
+	rl.Limit = limit
+	rl.Remaining = remaining
+	rl.ResetAt = time.Unix(reset, 0)
+	return rl, true
+}
@@ -0,0 +1,150 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-social/social/providers"
+)
+
+// Twitter search operators
+// See: https://developer.twitter.com/en/docs/tweets/search/guides/standard-operators
+var searchOperators = map[string]string{
+	"lang":       "lang:",
+	"since":      "since:",
+	"until":      "until:",
+	"min_likes":  "min_faves:",
+	"min_shares": "min_retweets:",
+}
+
+var searchHasFilters = map[string]string{
+	"media": "filter:media",
+	"link":  "filter:links",
+}
+
+// searchQuery returns the twitter search query of the query, and the
+// residual search expression to match on the results, see CompileSearch
+func searchQuery(query providers.Query) (string, providers.SearchNode, error) {
+	var q string
+	var residual providers.SearchNode
+	if query.Search.Expr != nil {
+		var err error
+		q, residual, err = providers.CompileSearch(query.Search.Expr, compileSearch)
+		if err != nil {
+			return "", nil, err
+		}
+	} else {
+		q = query.Search.Keywords(true)
+	}
+	q = strings.TrimSpace(q + " " + searchDateRange(query))
+
+	// Twitter rejects empty searches
+	if q == "" && residual != nil {
+		return "", nil, providers.ErrUnsupported.Err(fmt.Errorf("search: %q has no terms twitter can search", residual.String()))
+	}
+	if q == "" {
+		return "", nil, providers.ErrInvalidQuery.Err(errors.New("search: empty query"))
+	}
+	return q, residual, nil
+}
+
+// compileSearch translates a search expression node into twitter's
+// search syntax
+func compileSearch(node providers.SearchNode) (string, error) {
+	switch n := node.(type) {
+	case *providers.SearchAnd:
+		parts := make([]string, len(n.Nodes))
+		for i, c := range n.Nodes {
+			s, err := compileSearch(c)
+			if err != nil {
+				return "", err
+			}
+			if _, ok := c.(*providers.SearchOr); ok {
+				s = "(" + s + ")"
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, " "), nil
+
+	case *providers.SearchOr:
+		parts := make([]string, len(n.Nodes))
+		for i, c := range n.Nodes {
+			s, err := compileSearch(c)
+			if err != nil {
+				return "", err
+			}
+			if _, ok := c.(*providers.SearchAnd); ok {
+				s = "(" + s + ")"
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, " OR "), nil
+
+	case *providers.SearchNot:
+		// Twitter only negates single terms and some operators
+		switch c := n.Node.(type) {
+		case *providers.SearchTerm:
+			if c.Kind == providers.SearchUser {
+				return "-from:" + c.Value + " -@" + c.Value, nil
+			}
+		case *providers.SearchOperator:
+			switch c.Name {
+			case "since":
+				// Published before the date
+				return "until:" + c.Value, nil
+			case "until":
+				return "since:" + c.Value, nil
+			case "lang":
+				return "", providers.ErrUnsupported
+			}
+		default:
+			return "", providers.ErrUnsupported
+		}
+		s, err := compileSearch(n.Node)
+		if err != nil {
+			return "", err
+		}
+		return "-" + s, nil
+
+	case *providers.SearchTerm:
+		if n.Kind == providers.SearchUser {
+			// Posts from or mentioning the user, as with Query.Filter
+			return "(from:" + n.Value + " OR @" + n.Value + ")", nil
+		}
+		return n.String(), nil
+
+	case *providers.SearchOperator:
+		if n.Name == "has" {
+			if f, ok := searchHasFilters[n.Value]; ok {
+				return f, nil
+			}
+			return "", providers.ErrUnsupported
+		}
+		if op, ok := searchOperators[n.Name]; ok {
+			return op + n.Value, nil
+		}
+	}
+
+	return "", providers.ErrUnsupported
+}
+
+// searchDateRange returns the since: and until: operators of the query
+// bounds. Twitter only takes dates, so the range is widened to whole days,
+// and the exact bounds are applied to the results by the session.
+func searchDateRange(query providers.Query) string {
+	var ops []string
+	if !query.Since.IsZero() {
+		ops = append(ops, "since:"+query.Since.UTC().Format(providers.SearchDateLayout))
+	}
+	if !query.Until.IsZero() {
+		until := query.Until.UTC()
+		day := until.Truncate(24 * time.Hour)
+		if !day.Equal(until) {
+			day = day.Add(24 * time.Hour)
+		}
+		ops = append(ops, "until:"+day.Format(providers.SearchDateLayout))
+	}
+	return strings.Join(ops, " ")
+}
@@ -0,0 +1,72 @@
+package twitter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-social/social/providers"
+)
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		q        string
+		want     string
+		residual string
+	}{
+		{"go rust", "go rust", ""},
+		{"go OR rust", "go OR rust", ""},
+		{"a (b OR c)", "a (b OR c)", ""},
+		{`"hello world" #tag`, `"hello world" #tag`, ""},
+		{"@user", "(from:user OR @user)", ""},
+		{"go -@user", "go -from:user -@user", ""},
+		{"go lang:en has:media min_likes:5", "go lang:en filter:media min_faves:5", ""},
+		{"go -has:link -min_shares:5", "go -filter:links -min_retweets:5", ""},
+		{"go -since:2020-01-02", "go until:2020-01-02", ""},
+		{"go -until:2020-01-02", "go since:2020-01-02", ""},
+		{"go -lang:en", "go", "-lang:en"},
+		{"go -(a OR b)", "go", "-(a OR b)"},
+		{"go (a OR -lang:en)", "go", "a OR -lang:en"},
+	}
+	for _, tt := range tests {
+		q, residual, err := searchQuery(providers.NewQuery(map[string][]string{"q": {tt.q}}))
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.q, err)
+			continue
+		}
+		res := ""
+		if residual != nil {
+			res = residual.String()
+		}
+		if q != tt.want || res != tt.residual {
+			t.Errorf("%q: expecting %q, residual %q, got %q, residual %q", tt.q, tt.want, tt.residual, q, res)
+		}
+	}
+}
+
+func TestSearchQueryDateRange(t *testing.T) {
+	query := providers.NewQuery(map[string][]string{"q": {"go"}})
+	query.Since = time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC)
+	query.Until = time.Date(2020, 1, 5, 1, 0, 0, 0, time.UTC)
+
+	q, _, err := searchQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "go since:2020-01-02 until:2020-01-06"; q != want {
+		t.Errorf("expecting %q, got %q", want, q)
+	}
+}
+
+func TestSearchQueryEmpty(t *testing.T) {
+	// Twitter rejects searches without native terms
+	_, _, err := searchQuery(providers.NewQuery(map[string][]string{"q": {"-lang:en"}}))
+	if !errors.Is(err, providers.ErrUnsupported) {
+		t.Errorf("expecting ErrUnsupported, got %v", err)
+	}
+
+	_, _, err = searchQuery(providers.NewQuery(nil))
+	if !errors.Is(err, providers.ErrInvalidQuery) {
+		t.Errorf("expecting ErrInvalidQuery, got %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package twitter
+
+import "unicode/utf8"
+
+// weight returns the number of characters a character counts for in a
+// tweet: one for the latin scripts and the common punctuation, two for the
+// others, ie. CJK and emojis. Emoji sequences count for two as a whole.
+// Network docs: https://developer.twitter.com/en/docs/counting-characters
+func weight(char string) int {
+	r, n := utf8.DecodeRuneInString(char)
+	if n < len(char) {
+		return 2 // an emoji sequence
+	}
+	switch {
+	case r <= 0x10FF,
+		r >= 0x2000 && r <= 0x200D,
+		r >= 0x2010 && r <= 0x201F,
+		r >= 0x2032 && r <= 0x2037:
+		return 1
+	}
+	return 2
+}
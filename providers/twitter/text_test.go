@@ -0,0 +1,50 @@
+package twitter
+
+import (
+	"testing"
+
+	"github.com/go-social/social/providers"
+)
+
+func TestLength(t *testing.T) {
+	limits := providers.PostLimits{URLLength: 23, Weight: weight}
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"hello", 5},
+		{"café", 4},
+		{"café", 4}, // NFC normalized
+		{"日本語", 6},
+		{"go https://go.dev/doc/effective_go", 26},
+		{"👍", 2},
+		{"👍🏽", 2},      // skin tone
+		{"👩‍💻", 2},     // zero width joined
+		{"👨‍👩‍👧‍👦", 2}, // family
+		{"🇫🇷", 2},      // flag
+		{"🇫🇷🇩🇪", 4},    // two flags
+		{"1️⃣", 2},     // keycap
+		{"🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", 2}, // subdivision flag
+		{"a👍🏽b", 4},
+	}
+	for _, tt := range tests {
+		if got := limits.Length(tt.text); got != tt.want {
+			t.Errorf("%q: expecting %d, got %d", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestSplitEmojis(t *testing.T) {
+	// Emoji sequences aren't cut
+	limits := providers.PostLimits{MaxLength: 8, Weight: weight}
+	parts := limits.Split("👍🏽👍🏽👍🏽👍🏽👍🏽")
+	want := []string{"👍🏽👍🏽 1/3", "👍🏽👍🏽 2/3", "👍🏽 3/3"}
+	if len(parts) != len(want) {
+		t.Fatalf("expecting %q, got %q", want, parts)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("expecting %q, got %q", want[i], parts[i])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package twitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/ChimeraCoder/anaconda"
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+// maxThreadDepth is the number of parent tweets looked up at most to find
+// the root of a conversation
+const maxThreadDepth = 20
+
+// GetReplies returns the replies to a tweet. Twitter has no replies api, so
+// they're searched among the tweets to its author since the tweet, which
+// only go back 7 days with the standard search.
+func (p *Provider) GetReplies(ctx context.Context, postID string, query providers.Query) (social.Posts, *providers.Cursor, error) {
+	tweet, err := p.getTweet(postID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	posts, cursor, err := p.searchReplies(query, "to:"+tweet.User.ScreenName, postID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var replies social.Posts
+	for _, post := range posts {
+		if post.ParentID == postID {
+			replies.Add(post)
+		}
+	}
+	return replies, cursor, nil
+}
+
+// GetThread returns the conversation of a tweet, from the root tweet found
+// by following the in_reply_to tweets, and the replies searched among the
+// tweets to and mentioning the root author
+func (p *Provider) GetThread(ctx context.Context, postID string, query providers.Query) (*social.Post, *providers.Cursor, error) {
+	tweet, err := p.getTweet(postID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Walk up to the root, keeping the tweets on the way in the thread
+	var ancestors social.Posts
+	for i := 0; i < maxThreadDepth && tweet.InReplyToStatusIdStr != ""; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		parent, err := p.getTweet(tweet.InReplyToStatusIdStr)
+		if errors.Is(err, providers.ErrPostNotFound) {
+			// Deleted or protected, the thread starts here
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		ancestors.Add((Mapper{}).BuildPost(tweet))
+		tweet = parent
+	}
+	root := (Mapper{}).BuildPost(tweet)
+
+	author := root.Author.Username
+	posts, cursor, err := p.searchReplies(query, fmt.Sprintf("to:%s OR @%s", author, author), root.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	posts.Add(ancestors...)
+
+	return providers.BuildThread(root, posts), cursor, nil
+}
+
+func (p *Provider) getTweet(id string) (anaconda.Tweet, error) {
+	tweetID, err := parseID(id)
+	if err != nil {
+		return anaconda.Tweet{}, err
+	}
+	tweet, err := p.api.GetTweet(tweetID, url.Values{"include_entities": {"true"}, "tweet_mode": {"extended"}})
+	if err != nil {
+		return anaconda.Tweet{}, providerError(err)
+	}
+	return tweet, nil
+}
+
+// searchReplies searches the tweets of q posted after the tweet sinceID
+func (p *Provider) searchReplies(query providers.Query, q string, sinceID string) (social.Posts, *providers.Cursor, error) {
+	args := url.Values{}
+	args.Set("count", strconv.Itoa(query.Limit))
+	args.Set("include_entities", "true")
+	args.Set("result_type", "recent")
+	args.Set("tweet_mode", "extended")
+
+	args.Set("since_id", sinceID)
+	if query.SinceID != "" {
+		args.Set("since_id", query.SinceID)
+	}
+	if query.UntilID != "" {
+		args.Set("max_id", maxID(query.UntilID))
+	}
+
+	resp, err := p.api.GetSearch(q, args)
+	if err != nil {
+		return nil, nil, providerError(err)
+	}
+
+	posts := (Mapper{}).BuildPosts(resp.Statuses)
+	prev, next := getCursorIDs(posts)
+	cursor := providers.NewCursor(query, prev, next)
+
+	return posts, cursor, nil
+}
@@ -0,0 +1,83 @@
+package twitter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ChimeraCoder/anaconda"
+	"github.com/go-social/social"
+	"github.com/go-social/social/providers"
+)
+
+// conversation is a twitter conversation: 1 <- 2 <- 3, and 4 replying to a
+// tweet out of it
+var conversation = map[string]string{
+	"1": `{"id_str": "1", "full_text": "root", "user": {"id_str": "10", "screen_name": "alice"}}`,
+	"2": `{"id_str": "2", "full_text": "@alice reply", "in_reply_to_status_id_str": "1", "user": {"id_str": "20", "screen_name": "bob"}}`,
+	"3": `{"id_str": "3", "full_text": "@bob reply", "in_reply_to_status_id_str": "2", "user": {"id_str": "10", "screen_name": "alice"}}`,
+	"4": `{"id_str": "4", "full_text": "@alice other", "in_reply_to_status_id_str": "99", "user": {"id_str": "30", "screen_name": "carol"}}`,
+}
+
+func newTestProvider(t *testing.T) *Provider {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/statuses/show.json":
+			tweet, ok := conversation[r.URL.Query().Get("id")]
+			if !ok {
+				w.WriteHeader(404)
+				w.Write([]byte(`{"errors": [{"code": 144, "message": "No status found"}]}`))
+				return
+			}
+			w.Write([]byte(tweet))
+		case "/search/tweets.json":
+			// Newest first
+			w.Write([]byte(`{"statuses": [` + conversation["4"] + `,` + conversation["3"] + `,` + conversation["2"] + `]}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	api := anaconda.NewTwitterApi("token", "secret")
+	api.DisableThrottling()
+	api.SetBaseUrl(srv.URL)
+	return &Provider{api: api}
+}
+
+func postIDs(posts social.Posts) string {
+	var ids []string
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestGetReplies(t *testing.T) {
+	p := newTestProvider(t)
+	replies, _, err := p.GetReplies(context.Background(), "1", providers.Query{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := postIDs(replies); got != "2" {
+		t.Errorf("expecting the direct replies, got %s", got)
+	}
+}
+
+func TestGetThread(t *testing.T) {
+	p := newTestProvider(t)
+
+	// From a reply, up to the root
+	root, _, err := p.GetThread(context.Background(), "3", providers.Query{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.ID != "1" || postIDs(root.Replies) != "2" || postIDs(root.Replies[0].Replies) != "3" {
+		t.Errorf("unexpected thread %s -> %s", root.ID, postIDs(root.Replies))
+	}
+	if len(root.Replies[0].Replies[0].Replies) != 0 {
+		t.Errorf("expecting the tweet out of the conversation to be left out")
+	}
+}
@@ -33,8 +33,16 @@ type Provider struct {
 func New(ctx context.Context, creds social.Credentials) (providers.ProviderSession, error) {
 	api := anaconda.NewTwitterApi(creds.AccessToken(), creds.AccessTokenSecret())
 	api.ReturnRateLimitError(true)
+
+	// Track the rate limits twitter reports instead of anaconda's fixed
+	// client-side throttling
 	api.DisableThrottling()
-	api.HttpClient = http.DefaultClient
+	api.HttpClient = &http.Client{
+		Transport: &providers.RateLimitTransport{
+			Credential: creds.AccessToken(),
+			Parse:      parseRateLimit,
+		},
+	}
 	return &Provider{creds: creds, api: api}, nil
 }
 
@@ -52,6 +60,37 @@ func init() {
 		Configure: Configure,
 		New:       New,
 		NewOAuth:  NewOAuth,
+		Capabilities: providers.Capabilities{
+			Search:    true,
+			Feed:      true,
+			Posts:     true,
+			Friends:   true,
+			Followers: true,
+			Publish:   true,
+			Delete:    true,
+			Like:      true,
+			Share:     true,
+			Follow:    true,
+			Replies:   true,
+		},
+		Limits: providers.PostLimits{
+			MaxLength:  280,
+			URLLength:  23,
+			Weight:     weight,
+			AppendLink: true,
+			MaxImages:  maxImages,
+		},
+		EntityURLs: providers.EntityURLs{
+			Profile: func(username string) string {
+				return "https://twitter.com/" + url.PathEscape(username)
+			},
+			Hashtag: func(tag string) string {
+				return "https://twitter.com/hashtag/" + url.PathEscape(tag)
+			},
+			Cashtag: func(symbol string) string {
+				return "https://twitter.com/search?q=" + url.QueryEscape("$"+symbol)
+			},
+		},
 	})
 }
 
@@ -59,15 +98,47 @@ func (p *Provider) ID() string {
 	return ProviderID
 }
 
-// Post a tweet to twitter
-func (p *Provider) Post(ctx context.Context, msg string, shareLink string) (*social.Post, error) {
+func (p *Provider) Credentials() social.Credentials {
+	return p.creds
+}
+
+// Publish a tweet to twitter
+// Network docs: https://developer.twitter.com/en/docs/tweets/post-and-engage/api-reference/post-statuses-update
+func (p *Provider) Publish(ctx context.Context, req *providers.PostRequest) (*social.Post, error) {
+	if err := checkPostRequest(req); err != nil {
+		return nil, err
+	}
+	if err := providers.PrepareMedia(req.Media, maxImages); err != nil {
+		return nil, err
+	}
+
 	// Append the share link to the message
-	if shareLink != "" && strings.Index(msg, shareLink) < 0 {
-		msg = fmt.Sprintf("%s %s", strings.TrimSpace(msg), shareLink)
+	msg := req.Message
+	if req.Link != "" && strings.Index(msg, req.Link) < 0 {
+		msg = fmt.Sprintf("%s %s", strings.TrimSpace(msg), req.Link)
+	}
+
+	args := url.Values{}
+	for k, v := range req.Options {
+		args[k] = v
+	}
+	if req.ReplyTo != "" {
+		args.Set("in_reply_to_status_id", req.ReplyTo)
+		args.Set("auto_populate_reply_metadata", "true")
+	}
+	if req.QuoteURL != "" {
+		args.Set("attachment_url", req.QuoteURL)
+	}
+	if len(req.Media) > 0 {
+		ids, err := p.uploadMedia(ctx, req.Media)
+		if err != nil {
+			return nil, err
+		}
+		args.Set("media_ids", strings.Join(ids, ","))
 	}
 
 	// Send tweet
-	tweet, err := p.api.PostTweet(msg, url.Values{})
+	tweet, err := p.api.PostTweet(msg, args)
 	if err != nil {
 		perr := providerError(err)
 		return nil, perr
@@ -77,11 +148,42 @@ func (p *Provider) Post(ctx context.Context, msg string, shareLink string) (*soc
 		Provider: p.ID(),
 		ID:       tweet.IdStr,
 		URL:      fmt.Sprintf("https://twitter.com/statuses/%s", tweet.IdStr),
+		Media:    (Mapper{}).BuildMedia(tweet.ExtendedEntities.Media),
 	}
 
 	return newPost, nil
 }
 
+// DeletePost deletes a tweet of the user
+// Network docs: https://developer.twitter.com/en/docs/tweets/post-and-engage/api-reference/post-statuses-destroy-id
+func (p *Provider) DeletePost(ctx context.Context, id string) error {
+	tweetID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	_, err = p.api.DeleteTweet(tweetID, true)
+	return providerError(err)
+}
+
+// EditPost returns ErrUnsupported, tweets can't be edited
+func (p *Provider) EditPost(ctx context.Context, id string, req *providers.PostRequest) (*social.Post, error) {
+	return nil, providers.ErrUnsupported
+}
+
+// checkPostRequest rejects the post fields twitter doesn't support
+func checkPostRequest(req *providers.PostRequest) error {
+	switch {
+	case req.LinkPreview != nil:
+		return providers.ErrUnsupportedField(ProviderID, "link preview")
+	case req.Visibility != "" && req.Visibility != providers.VisibilityPublic:
+		// Tweets are as visible as the account, protected or not
+		return providers.ErrUnsupportedField(ProviderID, "visibility")
+	case req.ScheduledAt != nil:
+		return providers.ErrUnsupportedField(ProviderID, "scheduled time")
+	}
+	return nil
+}
+
 // Search Twitter via their REST API
 func (p *Provider) Search(query providers.Query) (social.Posts, *providers.Cursor, error) {
 	var tweets []anaconda.Tweet
@@ -91,6 +193,9 @@ func (p *Provider) Search(query providers.Query) (social.Posts, *providers.Curso
 	args.Add("count", strconv.Itoa(query.Limit))
 	args.Add("include_entities", "true")
 
+	// full_text, instead of the text truncated to 140 characters
+	args.Set("tweet_mode", "extended")
+
 	// remove retweets from a user's timeline
 	args.Set("include_rts", "false")
 
@@ -105,13 +210,15 @@ func (p *Provider) Search(query providers.Query) (social.Posts, *providers.Curso
 	}
 
 	if query.UntilID != "" {
-		args.Add("max_id", query.UntilID)
+		args.Add("max_id", maxID(query.UntilID))
 	}
 	if query.SinceID != "" {
 		args.Add("since_id", query.SinceID)
 	}
 
-	if query.Search.Username() != "" {
+	var residual providers.SearchNode
+
+	if query.Search.Username() != "" && query.Search.OnlyUsernames() {
 		// See: https://dev.twitter.com/rest/reference/get/statuses/user_timeline
 		args.Set("screen_name", query.Search.Username())
 
@@ -122,7 +229,15 @@ func (p *Provider) Search(query providers.Query) (social.Posts, *providers.Curso
 		tweets, err = p.api.GetUserTimeline(args)
 
 	} else {
-		q := query.Search.Keywords(true)
+		var q string
+		q, residual, err = searchQuery(query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if query.Lang != "" {
+			args.Set("lang", query.Lang)
+		}
 
 		var resp anaconda.SearchResponse
 		resp, err = p.api.GetSearch(q, args)
@@ -138,6 +253,10 @@ func (p *Provider) Search(query providers.Query) (social.Posts, *providers.Curso
 	prev, next := getCursorIDs(posts)
 	cursor := providers.NewCursor(query, prev, next)
 
+	// Apply the search terms twitter doesn't support natively, after
+	// computing the cursor from the unfiltered page
+	posts = providers.MatchPosts(posts, residual)
+
 	return posts, cursor, nil
 }
 
@@ -146,8 +265,9 @@ func (p *Provider) GetFeed(query providers.Query) (social.Posts, *providers.Curs
 	args := url.Values{}
 
 	args.Add("count", strconv.Itoa(query.Limit))
+	args.Set("tweet_mode", "extended")
 	if query.UntilID != "" {
-		args.Add("max_id", query.UntilID)
+		args.Add("max_id", maxID(query.UntilID))
 	}
 	if query.SinceID != "" {
 		args.Add("since_id", query.SinceID)
@@ -171,8 +291,9 @@ func (p *Provider) GetPosts(query providers.Query) (social.Posts, *providers.Cur
 	args := url.Values{}
 
 	args.Add("count", strconv.Itoa(query.Limit))
+	args.Set("tweet_mode", "extended")
 	if query.UntilID != "" {
-		args.Add("max_id", query.UntilID)
+		args.Add("max_id", maxID(query.UntilID))
 	}
 	if query.SinceID != "" {
 		args.Add("since_id", query.SinceID)
@@ -243,7 +364,7 @@ func (p *Provider) GetFriends(query providers.Query) ([]*social.User, *providers
 	}
 
 	users := (UserMapper{}).BuildUsers(q.Users)
-	cursor := providers.NewCursor(query, q.Previous_cursor_str, q.Next_cursor_str)
+	cursor := providers.NewCursor(query, listCursor(q.Previous_cursor_str), listCursor(q.Next_cursor_str))
 
 	return users, cursor, nil
 }
@@ -276,7 +397,7 @@ func (p *Provider) GetFollowers(query providers.Query) ([]*social.User, *provide
 	}
 
 	users := (UserMapper{}).BuildUsers(q.Users)
-	cursor := providers.NewCursor(query, q.Previous_cursor_str, q.Next_cursor_str)
+	cursor := providers.NewCursor(query, listCursor(q.Previous_cursor_str), listCursor(q.Next_cursor_str))
 
 	return users, cursor, nil
 }
@@ -289,3 +410,22 @@ func getCursorIDs(posts social.Posts) (prevID, nextID string) {
 	nextID = posts[len(posts)-1].ID
 	return
 }
+
+// maxID returns the twitter max_id for the next page, which is inclusive,
+// from the last post id of the previous page
+func maxID(untilID string) string {
+	id, err := strconv.ParseInt(untilID, 10, 64)
+	if err != nil || id <= 0 {
+		return untilID
+	}
+	return strconv.FormatInt(id-1, 10)
+}
+
+// listCursor returns the cursor of a friends/followers list page, twitter
+// uses "0" when there are no more pages
+func listCursor(cursor string) string {
+	if cursor == "0" {
+		return ""
+	}
+	return cursor
+}
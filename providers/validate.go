@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/go-social/social"
+)
+
+// PostValidation is the report of ValidatePost
+type PostValidation struct {
+	// Length of the message, weighted as the provider counts it
+	Length    int `json:"length"`
+	MaxLength int `json:"max_length,omitempty"`
+
+	// Remaining characters, negative when the message overflows, or 0
+	// when the length is unlimited
+	Remaining int `json:"remaining"`
+
+	Mentions int `json:"mentions"`
+	Hashtags int `json:"hashtags"`
+
+	// Truncated is the message truncated to fit, with an ellipsis, when
+	// it overflows
+	Truncated string `json:"truncated,omitempty"`
+
+	// Errors are the reasons the provider would reject the post:
+	// ErrPostTooLong, ErrInvalidAsset or ErrTooManyEntities
+	Errors []error `json:"-"`
+}
+
+// Valid reports whether the post fits the provider limits
+func (v *PostValidation) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// Err returns the validation errors joined, or nil when the post is valid
+func (v *PostValidation) Err() error {
+	return errors.Join(v.Errors...)
+}
+
+// ValidatePost checks the post request against the limits of a provider,
+// before publishing it: the weighted length of the message, the media and
+// the number of mentions and hashtags. It only returns an error for an
+// unknown provider, the post errors are reported in the validation.
+//
+// Sessions whose limits depend on their account or instance are validated
+// with their LimitsOfSession, see PostLimits.Validate.
+func ValidatePost(providerID string, req *PostRequest) (*PostValidation, error) {
+	limits, err := LimitsOf(providerID)
+	if err != nil {
+		return nil, err
+	}
+	return limits.Validate(req), nil
+}
+
+// Validate checks the post request against the limits, see ValidatePost.
+// The request is left unchanged.
+func (l PostLimits) Validate(req *PostRequest) *PostValidation {
+	text := req.Message
+	if l.AppendLink && req.Link != "" && !strings.Contains(text, req.Link) {
+		text = strings.TrimSpace(text) + " " + req.Link
+	}
+
+	v := &PostValidation{
+		Length:    l.Length(text),
+		MaxLength: l.MaxLength,
+	}
+	if l.MaxLength > 0 {
+		v.Remaining = l.MaxLength - v.Length
+	}
+
+	if l.MaxLength > 0 && v.Remaining < 0 {
+		v.Errors = append(v.Errors, ErrPostTooLong.Err(fmt.Errorf("%d characters over %d", -v.Remaining, l.MaxLength)))
+		v.Truncated = l.Truncate(req.Message, l.MaxLength-(v.Length-l.Length(req.Message)))
+	}
+
+	// Checked on copies, as preparing the uploads fills them in
+	media := make([]*MediaUpload, len(req.Media))
+	for i, m := range req.Media {
+		c := *m
+		media[i] = &c
+	}
+	if err := PrepareMedia(media, l.MaxImages); err != nil {
+		v.Errors = append(v.Errors, err)
+	}
+
+	for _, e := range ExtractEntities(text) {
+		switch e.Type {
+		case social.EntityMention:
+			v.Mentions++
+		case social.EntityHashtag:
+			v.Hashtags++
+		}
+	}
+	if l.MaxMentions > 0 && v.Mentions > l.MaxMentions {
+		v.Errors = append(v.Errors, ErrTooManyEntities.Err(fmt.Errorf("%d mentions over %d", v.Mentions, l.MaxMentions)))
+	}
+	if l.MaxHashtags > 0 && v.Hashtags > l.MaxHashtags {
+		v.Errors = append(v.Errors, ErrTooManyEntities.Err(fmt.Errorf("%d hashtags over %d", v.Hashtags, l.MaxHashtags)))
+	}
+
+	return v
+}
+
+// Truncate returns the text cut between words to fit max, with an ellipsis
+func (l PostLimits) Truncate(text string, max int) string {
+	text = strings.TrimSpace(text)
+	if l.Length(text) <= max {
+		return text
+	}
+	if max <= 1 {
+		return ""
+	}
+	parts := l.split(text, max-l.Length("…"))
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.TrimRightFunc(parts[0], func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
@@ -0,0 +1,143 @@
+package providers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-social/social"
+)
+
+func TestValidate(t *testing.T) {
+	limits := PostLimits{MaxLength: 10, AppendLink: true, MaxHashtags: 1}
+
+	v := limits.Validate(&PostRequest{Message: "hello"})
+	if !v.Valid() || v.Length != 5 || v.Remaining != 5 {
+		t.Errorf("unexpected validation %+v", v)
+	}
+
+	v = limits.Validate(&PostRequest{Message: "hello #go #rust", Link: "www.go.dev"})
+	if v.Valid() || v.Remaining != -16 || v.Hashtags != 2 {
+		t.Errorf("unexpected validation %+v", v)
+	}
+	if !errors.Is(v.Err(), ErrPostTooLong) || !errors.Is(v.Err(), ErrTooManyEntities) {
+		t.Errorf("expecting ErrPostTooLong and ErrTooManyEntities, got %v", v.Err())
+	}
+
+	// Unlimited length
+	limits.MaxLength = 0
+	v = limits.Validate(&PostRequest{Message: strings.Repeat("a", 1000)})
+	if !v.Valid() || v.Length != 1000 || v.Remaining != 0 {
+		t.Errorf("expecting no remaining characters when unlimited, got %+v", v)
+	}
+}
+
+func TestValidateLength(t *testing.T) {
+	// Links count for URLLength, whatever their length
+	limits := PostLimits{MaxLength: 30, URLLength: 23}
+	v := limits.Validate(&PostRequest{Message: "see https://example.com/" + strings.Repeat("a", 100)})
+	if !v.Valid() || v.Length != 4+23 {
+		t.Errorf("expecting the link to count for 23, got %+v", v)
+	}
+	v = limits.Validate(&PostRequest{Message: "at www.go.dev."})
+	if v.Length != 3+23+1 {
+		t.Errorf("expecting the trailing dot out of the link, got %d", v.Length)
+	}
+
+	// CJK characters count double
+	limits = PostLimits{MaxLength: 10, Weight: cjkWeight}
+	v = limits.Validate(&PostRequest{Message: "こんにちは"})
+	if !v.Valid() || v.Length != 10 || v.Remaining != 0 {
+		t.Errorf("expecting 5 CJK characters to fit, got %+v", v)
+	}
+	v = limits.Validate(&PostRequest{Message: "こんにちは!"})
+	if v.Valid() || v.Remaining != -1 {
+		t.Errorf("expecting an overflow, got %+v", v)
+	}
+}
+
+func TestValidateTruncated(t *testing.T) {
+	limits := PostLimits{MaxLength: 10}
+	v := limits.Validate(&PostRequest{Message: "hello wonderful world"})
+	if v.Truncated != "hello…" {
+		t.Errorf("expecting the message cut between words, got %q", v.Truncated)
+	}
+	if v = limits.Validate(&PostRequest{Message: "hello"}); v.Truncated != "" {
+		t.Errorf("expecting no suggestion when the message fits, got %q", v.Truncated)
+	}
+
+	// Leaving room for the appended link
+	limits = PostLimits{MaxLength: 20, URLLength: 10, AppendLink: true}
+	req := &PostRequest{Message: "hello wonderful world", Link: "https://go.dev/doc"}
+	v = limits.Validate(req)
+	if v.Truncated != "hello…" {
+		t.Errorf("expecting the message cut before the link, got %q", v.Truncated)
+	}
+	if v = limits.Validate(&PostRequest{Message: v.Truncated, Link: req.Link}); !v.Valid() {
+		t.Errorf("expecting the truncated message to fit, got %+v", v)
+	}
+
+	// Weighted
+	limits = PostLimits{MaxLength: 10, Weight: cjkWeight}
+	v = limits.Validate(&PostRequest{Message: "日本 こんにちは 世界"})
+	if v.Truncated != "日本…" || limits.Length(v.Truncated) > 10 {
+		t.Errorf("expecting the weighted message cut, got %q", v.Truncated)
+	}
+}
+
+func TestValidateMedia(t *testing.T) {
+	png := func() *MediaUpload { return &MediaUpload{Data: []byte("\x89PNG\r\n\x1a\n")} }
+	video := func() *MediaUpload { return &MediaUpload{ContentType: "video/mp4", Data: []byte("video")} }
+	gif := func() *MediaUpload { return &MediaUpload{Data: []byte("GIF89a")} }
+
+	limits := PostLimits{MaxImages: 4}
+	tests := []struct {
+		media []*MediaUpload
+		valid bool
+	}{
+		{[]*MediaUpload{png(), png(), png(), png()}, true},
+		{[]*MediaUpload{png(), png(), png(), png(), png()}, false},
+		{[]*MediaUpload{video()}, true},
+		{[]*MediaUpload{gif()}, true},
+		{[]*MediaUpload{png(), video()}, false},
+		{[]*MediaUpload{gif(), png()}, false},
+		{[]*MediaUpload{{Filename: "empty.png"}}, false},
+		{[]*MediaUpload{{Data: []byte("plain text")}}, false},
+	}
+	for i, tt := range tests {
+		v := limits.Validate(&PostRequest{Media: tt.media})
+		if v.Valid() != tt.valid {
+			t.Errorf("%d: expecting valid %v, got %v", i, tt.valid, v.Err())
+		}
+		if !v.Valid() && !errors.Is(v.Err(), ErrInvalidAsset) {
+			t.Errorf("%d: expecting ErrInvalidAsset, got %v", i, v.Err())
+		}
+	}
+
+	// Any number of images when unlimited
+	limits.MaxImages = 0
+	if v := limits.Validate(&PostRequest{Media: []*MediaUpload{png(), png(), png(), png(), png()}}); !v.Valid() {
+		t.Errorf("expecting any number of images without MaxImages, got %v", v.Err())
+	}
+
+	// The uploads are checked on copies
+	req := &PostRequest{Media: []*MediaUpload{png()}}
+	limits.Validate(req)
+	if m := req.Media[0]; m.ContentType != "" || m.Type != "" || m.Filename != "" {
+		t.Errorf("expecting the request media to be left unchanged, got %+v", m)
+	}
+	if err := PrepareMedia(req.Media, 0); err != nil || req.Media[0].Type != social.MediaImage {
+		t.Errorf("expecting the media to be prepared when publishing, got %v, %+v", err, req.Media[0])
+	}
+}
+
+// cjkWeight counts the CJK characters double, as twitter does
+func cjkWeight(char string) int {
+	r, _ := utf8.DecodeRuneInString(char)
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return 2
+	}
+	return 1
+}
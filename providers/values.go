@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valuesDecoder is the decoding counterpart of go-querystring's
+// query.Encoder, for types which decode themselves from url values
+type valuesDecoder interface {
+	DecodeValues(key string, v url.Values) error
+}
+
+var (
+	valuesDecoderType = reflect.TypeOf((*valuesDecoder)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// decodeValues sets the fields of the struct pointed to by v from their
+// `url` tagged args, as encoded by go-querystring. Fields missing from the
+// args are left untouched. All the fields are decoded, and the first error
+// is returned.
+func decodeValues(args url.Values, v interface{}) error {
+	val := reflect.ValueOf(v).Elem()
+	typ := val.Type()
+
+	var firstErr error
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		name := valuesKey(sf)
+		if name == "" {
+			continue
+		}
+
+		fv := val.Field(i)
+		if fv.Addr().Type().Implements(valuesDecoderType) {
+			if err := fv.Addr().Interface().(valuesDecoder).DecodeValues(name, args); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		s, ok := args[name]
+		if !ok || len(s) == 0 {
+			continue
+		}
+		if err := decodeValue(fv, s[0]); err != nil && firstErr == nil {
+			firstErr = ErrInvalidQuery.Err(fmt.Errorf("%s: %v", name, err))
+		}
+	}
+	return firstErr
+}
+
+func decodeValue(fv reflect.Value, s string) error {
+	if fv.Type() == timeType {
+		t, err := parseQueryTime(s)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// valuesKeys returns the url args names of the `url` tagged fields of t
+func valuesKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := valuesKey(t.Field(i)); name != "" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+func valuesKey(sf reflect.StructField) string {
+	if sf.PkgPath != "" {
+		return "" // unexported
+	}
+	tag := sf.Tag.Get("url")
+	if tag == "-" {
+		return ""
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = sf.Name
+	}
+	return name
+}
@@ -36,7 +36,7 @@ type User struct {
 	ProfileURL   string     `json:"profile_url" url:"profile_url"`
 	AvatarURL    string     `json:"avatar_url" url:"avatar_url"`
 	NumPosts     int32      `json:"num_posts" url:"num_posts"`
-	NumFollowers int32      `json:"num_followers" url:"num_folowers"`
+	NumFollowers int32      `json:"num_followers" url:"num_followers"`
 	NumFollowing int32      `json:"num_following" url:"num_following"`
 	Lang         string     `json:"lang" url:"lang"`
 	Location     string     `json:"location" url:"location"`